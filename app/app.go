@@ -0,0 +1,159 @@
+// Package app wires the databases, repositories, background jobs and routes
+// of the product API into one application. An App owns all of its
+// resources, so several can run in one process.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"product-api/database"
+	"product-api/jobs"
+	"product-api/repository"
+	"product-api/retention"
+	"product-api/routes"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultAddr is the address the server listens on unless configured otherwise
+const DefaultAddr = ":8080"
+
+// Config holds the settings of an application
+type Config struct {
+	Addr             string          // Address the HTTP server listens on, port 0 picks a free one
+	DatabasePath     string          // SQLite file storing the products
+	JobsDatabasePath string          // SQLite file storing the background jobs
+	DatabaseLogLevel logger.LogLevel // Level of the SQL statements logged by GORM
+	SwaggerHost      string          // Host the Swagger UI sends requests to
+	Routes           routes.Config
+	Jobs             jobs.Config
+	Retention        retention.Config
+}
+
+// App is a product API instance. New opens its databases and sets up its
+// routes; Start serves requests and runs the background work until Stop.
+type App struct {
+	config       Config
+	db           *gorm.DB
+	jobsDB       *gorm.DB
+	products     *repository.ProductRepository
+	searchConfig *repository.SearchConfigRepository
+	jobs         *jobs.Manager
+	server       *fiber.App
+
+	listener      net.Listener
+	stopRetention func()
+}
+
+// New opens and migrates the databases of config and builds the
+// repositories, job manager and routes on top of them. Without a job
+// directory the one of the job database is used, see jobs.DirFor.
+func New(config Config) (*App, error) {
+	if config.Jobs.Dir == "" {
+		config.Jobs.Dir = jobs.DirFor(config.JobsDatabasePath)
+	}
+	// Fill in the route defaults once, so all routes sign cursors with the same key
+	config.Routes = config.Routes.WithDefaults()
+
+	db, err := database.Open(config.DatabasePath, config.DatabaseLogLevel)
+	if err != nil {
+		return nil, err
+	}
+	jobsDB, err := database.OpenJobs(config.JobsDatabasePath, config.DatabaseLogLevel)
+	if err != nil {
+		database.Close(db)
+		return nil, err
+	}
+
+	a := &App{
+		config:       config,
+		db:           db,
+		jobsDB:       jobsDB,
+		products:     repository.NewProductRepository(db),
+		searchConfig: repository.NewSearchConfigRepository(db),
+		jobs:         jobs.NewManager(jobsDB, config.Jobs),
+	}
+
+	// Create a Fiber app that answers errors with problem details
+	a.server = fiber.New(fiber.Config{
+		ErrorHandler: routes.ErrorHandler,
+	})
+	routes.SetupMiddleware(a.server)
+	routes.SetupProductRoutes(a.server, a.products, config.Routes)
+	routes.SetupAdminRoutes(a.server, a.searchConfig, config.Routes)
+	routes.SetupJobRoutes(a.server, a.jobs, a.products, config.Routes)
+	routes.SetupSwaggerRoutes(a.server, config.SwaggerHost)
+	return a, nil
+}
+
+// Server returns the Fiber app serving the routes, e.g. to send it test
+// requests without starting the application
+func (a *App) Server() *fiber.App {
+	return a.server
+}
+
+// Addr returns the address the server listens on, or nil before Start
+func (a *App) Addr() net.Addr {
+	if a.listener == nil {
+		return nil
+	}
+	return a.listener.Addr()
+}
+
+// Start listens on the configured address, resumes the jobs interrupted by
+// the last shutdown, starts the job workers and the trash retention, and
+// serves requests in the background. It returns once the server accepts
+// connections; ctx only bounds the startup.
+func (a *App) Start(ctx context.Context) error {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", a.config.Addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", a.config.Addr, err)
+	}
+	if err := a.jobs.Start(); err != nil {
+		listener.Close()
+		return fmt.Errorf("cannot start job workers: %w", err)
+	}
+
+	// Permanently delete products that stayed in the trash too long
+	a.stopRetention = retention.Start(a.products, a.config.Retention)
+
+	a.listener = listener
+	go func() {
+		if err := a.server.Listener(listener); err != nil {
+			log.Printf("Server stopped: %v", err)
+		}
+	}()
+	log.Printf("Server listening on %s", listener.Addr())
+	return nil
+}
+
+// Stop shuts the server down, waiting for open requests until ctx is done,
+// stops the background work and closes the databases. Interrupted jobs are
+// resumed by the next Start.
+func (a *App) Stop(ctx context.Context) error {
+	var errs []error
+	if a.listener != nil {
+		if err := a.server.ShutdownWithContext(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("cannot shut down server: %w", err))
+		}
+	}
+	a.jobs.Stop()
+	if a.stopRetention != nil {
+		a.stopRetention()
+	}
+
+	if err := database.Close(a.jobsDB); err != nil {
+		errs = append(errs, fmt.Errorf("cannot close job database: %w", err))
+	}
+	if err := database.Close(a.db); err != nil {
+		errs = append(errs, fmt.Errorf("cannot close database: %w", err))
+	}
+	log.Println("Database connection closed")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,216 @@
+// Package config builds the configuration of the product API from, in
+// increasing order of precedence, built-in defaults, a YAML file, environment
+// variables and command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"product-api/app"
+	"product-api/database"
+	"product-api/jobs"
+	"product-api/retention"
+	"product-api/routes"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secrets in printed configurations
+const redacted = "[redacted]"
+
+// Config is the complete configuration of the product API
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Database Database `yaml:"database"`
+	Swagger  Swagger  `yaml:"swagger"`
+	Security Security `yaml:"security"`
+	Jobs     Jobs     `yaml:"jobs"`
+	Trash    Trash    `yaml:"trash"`
+
+	// CacheControl is the Cache-Control header of every cacheable route, see
+	// routes.DefaultCachePolicies; an empty value omits the header
+	CacheControl map[string]string `yaml:"cache_control"`
+}
+
+// Server holds the HTTP server settings
+type Server struct {
+	Addr            string   `yaml:"addr"`             // Address to listen on, e.g. ":8080"
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"` // How long open requests may take to finish on shutdown
+}
+
+// Database holds the SQLite settings
+type Database struct {
+	Path     string `yaml:"path"`      // File storing the products
+	JobsPath string `yaml:"jobs_path"` // File storing the background jobs
+	LogLevel string `yaml:"log_level"` // GORM log level: silent, error, warn or info
+}
+
+// Swagger holds the API documentation settings
+type Swagger struct {
+	Host string `yaml:"host"` // Host and optional port the Swagger UI sends requests to
+}
+
+// Security holds the secrets, which are redacted when printed
+type Security struct {
+	AdminToken   string `yaml:"admin_token"`   // Bearer token of the admin endpoints, empty disables them
+	CursorSecret string `yaml:"cursor_secret"` // Key signing pagination cursors, random if empty
+}
+
+// Jobs holds the background job settings
+type Jobs struct {
+	Workers     int    `yaml:"workers"`      // Number of jobs run at the same time
+	Dir         string `yaml:"dir"`          // Directory for uploaded inputs and results, derived from database.jobs_path if empty
+	MaxAttempts int    `yaml:"max_attempts"` // Starts of a resumable job before it is marked failed
+}
+
+// Trash holds the trash retention settings
+type Trash struct {
+	RetentionDays int      `yaml:"retention_days"` // Days products stay in the trash, 0 keeps them
+	PurgeInterval Duration `yaml:"purge_interval"` // How often the trash is checked
+}
+
+// Duration is a time.Duration written as a Go duration string such as "30m"
+type Duration time.Duration
+
+// MarshalText formats the duration like time.Duration.String
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses a duration with time.ParseDuration
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the configuration used when nothing is configured
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:            app.DefaultAddr,
+			ShutdownTimeout: Duration(10 * time.Second),
+		},
+		Database: Database{
+			Path:     database.DefaultPath,
+			JobsPath: database.DefaultJobsPath,
+			LogLevel: "info",
+		},
+		Swagger: Swagger{Host: "localhost:8080"},
+		Jobs: Jobs{
+			Workers:     jobs.DefaultWorkers,
+			MaxAttempts: jobs.DefaultMaxAttempts,
+		},
+		Trash: Trash{
+			RetentionDays: retention.DefaultRetentionDays,
+			PurgeInterval: Duration(retention.DefaultInterval),
+		},
+		CacheControl: maps.Clone(routes.DefaultCachePolicies),
+	}
+}
+
+// Validate checks every setting and returns all problems joined into one
+// error, each prefixed with the key of the setting
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.Server.Addr); err != nil || port == "" {
+		invalid("server.addr", "%q is not a host:port address", c.Server.Addr)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout", "must be positive")
+	}
+	if c.Database.Path == "" {
+		invalid("database.path", "must not be empty")
+	}
+	if c.Database.JobsPath == "" {
+		invalid("database.jobs_path", "must not be empty")
+	} else if c.Database.JobsPath == c.Database.Path {
+		invalid("database.jobs_path", "must differ from database.path")
+	}
+	if _, err := database.ParseLogLevel(c.Database.LogLevel); err != nil {
+		invalid("database.log_level", "%v", err)
+	}
+	if c.Swagger.Host == "" || strings.Contains(c.Swagger.Host, "/") {
+		invalid("swagger.host", "%q is not a host with an optional port", c.Swagger.Host)
+	}
+	if c.Jobs.Workers <= 0 {
+		invalid("jobs.workers", "must be positive")
+	}
+	if c.Jobs.MaxAttempts <= 0 {
+		invalid("jobs.max_attempts", "must be positive")
+	}
+	if c.Trash.RetentionDays < 0 {
+		invalid("trash.retention_days", "must not be negative")
+	}
+	if c.Trash.PurgeInterval <= 0 {
+		invalid("trash.purge_interval", "must be positive")
+	}
+	for route := range c.CacheControl {
+		if _, ok := routes.DefaultCachePolicies[route]; !ok {
+			invalid("cache_control."+route, "unknown route")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the configuration with the secrets that are
+// set replaced, safe to print or log
+func (c Config) Redacted() Config {
+	if c.Security.AdminToken != "" {
+		c.Security.AdminToken = redacted
+	}
+	if c.Security.CursorSecret != "" {
+		c.Security.CursorSecret = redacted
+	}
+	return c
+}
+
+// Print writes the configuration as YAML with its secrets redacted. The
+// output can be used as a configuration file once the secrets are filled in.
+func (c Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// App returns the settings of the application. The configuration must be
+// valid.
+func (c Config) App() app.Config {
+	logLevel, _ := database.ParseLogLevel(c.Database.LogLevel)
+	return app.Config{
+		Addr:             c.Server.Addr,
+		DatabasePath:     c.Database.Path,
+		JobsDatabasePath: c.Database.JobsPath,
+		DatabaseLogLevel: logLevel,
+		SwaggerHost:      c.Swagger.Host,
+		Routes: routes.Config{
+			AdminToken:    c.Security.AdminToken,
+			CursorSecret:  []byte(c.Security.CursorSecret),
+			CachePolicies: c.CacheControl,
+		},
+		Jobs: jobs.Config{
+			Workers:     c.Jobs.Workers,
+			Dir:         c.Jobs.Dir,
+			MaxAttempts: c.Jobs.MaxAttempts,
+		},
+		Retention: retention.Config{
+			Retention: time.Duration(c.Trash.RetentionDays) * 24 * time.Hour,
+			Interval:  time.Duration(c.Trash.PurgeInterval),
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"product-api/routes"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is a configuration value that can be set by an environment
+// variable or a command-line flag
+type setting struct {
+	env       string     // Environment variable, empty if none
+	flag      string     // Command-line flag, empty for secrets, which do not belong in the process list
+	usage     string     // Description shown by -help
+	value     flag.Value // Parses the value into the Config
+	keepEmpty bool       // An empty environment variable is a value rather than unset
+}
+
+// settings lists the settings of c that can be set by environment variables
+// and flags. The values write into c.
+func (c *Config) settings() []setting {
+	settings := []setting{
+		{env: "ADDR", flag: "addr", usage: "address the HTTP server listens on", value: (*stringValue)(&c.Server.Addr)},
+		{env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "how long open requests may take to finish on shutdown", value: &c.Server.ShutdownTimeout},
+		{env: "DB_PATH", flag: "db", usage: "SQLite file storing the products", value: (*stringValue)(&c.Database.Path)},
+		{env: "JOBS_DB_PATH", flag: "jobs-db", usage: "SQLite file storing the background jobs", value: (*stringValue)(&c.Database.JobsPath)},
+		{env: "DB_LOG_LEVEL", flag: "db-log-level", usage: "GORM log level: silent, error, warn or info", value: (*stringValue)(&c.Database.LogLevel)},
+		{env: "SWAGGER_HOST", flag: "swagger-host", usage: "host and optional port the Swagger UI sends requests to", value: (*stringValue)(&c.Swagger.Host)},
+		{env: "ADMIN_TOKEN", value: (*stringValue)(&c.Security.AdminToken)},
+		{env: "CURSOR_SECRET", value: (*stringValue)(&c.Security.CursorSecret)},
+		{env: "JOB_WORKERS", flag: "job-workers", usage: "number of jobs run at the same time", value: (*intValue)(&c.Jobs.Workers)},
+		{env: "JOB_DIR", flag: "job-dir", usage: "directory for job inputs and results", value: (*stringValue)(&c.Jobs.Dir)},
+		{env: "JOB_MAX_ATTEMPTS", flag: "job-max-attempts", usage: "starts of a resumable job before it is marked failed", value: (*intValue)(&c.Jobs.MaxAttempts)},
+		{env: "TRASH_RETENTION_DAYS", flag: "trash-retention-days", usage: "days products stay in the trash, 0 keeps them", value: (*intValue)(&c.Trash.RetentionDays)},
+		{env: "TRASH_PURGE_INTERVAL", flag: "trash-purge-interval", usage: "how often the trash is checked", value: &c.Trash.PurgeInterval},
+	}
+	for _, route := range slices.Sorted(maps.Keys(routes.DefaultCachePolicies)) {
+		settings = append(settings, setting{
+			env:       "CACHE_CONTROL_" + strings.ToUpper(route),
+			value:     mapValue{values: c.CacheControl, key: route},
+			keepEmpty: true,
+		})
+	}
+	return settings
+}
+
+// Load returns the configuration given by the defaults, the YAML file named
+// by the -config flag or CONFIG_FILE, the environment and the flags in args,
+// each overriding the ones before. The result is not validated. -help
+// returns flag.ErrHelp after printing the flags.
+func Load(args []string) (Config, error) {
+	// The flags are parsed first to find the configuration file, but applied last
+	defaults := Default()
+	flags := flag.NewFlagSet("product-api", flag.ContinueOnError)
+	configFile := flags.String("config", "", "YAML configuration file (env CONFIG_FILE)")
+	for _, s := range defaults.settings() {
+		if s.flag != "" {
+			flags.String(s.flag, s.value.String(), fmt.Sprintf("%s (env %s)", s.usage, s.env))
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	config := Default()
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := config.readFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	settings := config.settings()
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env)
+		if !ok || (value == "" && !s.keepEmpty) {
+			continue
+		}
+		if err := s.value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", s.env, value, err))
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := s.value.Set(f.Value.String()); err != nil {
+					errs = append(errs, fmt.Errorf("invalid -%s %q: %w", f.Name, f.Value, err))
+				}
+			}
+		}
+	})
+	return config, errors.Join(errs...)
+}
+
+// readFile merges the YAML file at path into the configuration. Unknown
+// keys are rejected, so misspelled settings do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if c.CacheControl == nil {
+		c.CacheControl = maps.Clone(routes.DefaultCachePolicies)
+	}
+	return nil
+}
+
+// stringValue is a string setting
+type stringValue string
+
+func (v *stringValue) String() string { return string(*v) }
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(strings.TrimSpace(s))
+	return nil
+}
+
+// intValue is an integer setting
+type intValue int
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return errors.New("not an integer")
+	}
+	*v = intValue(n)
+	return nil
+}
+
+// String formats the duration like time.Duration.String
+func (d Duration) String() string {
+	text, _ := d.MarshalText()
+	return string(text)
+}
+
+// Set parses a duration setting
+func (d *Duration) Set(s string) error {
+	return d.UnmarshalText([]byte(strings.TrimSpace(s)))
+}
+
+// mapValue is a setting stored under a key of a map
+type mapValue struct {
+	values map[string]string
+	key    string
+}
+
+func (v mapValue) String() string { return v.values[v.key] }
+
+func (v mapValue) Set(s string) error {
+	v.values[v.key] = strings.TrimSpace(s)
+	return nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"product-api/gtin"
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// backfillGTIN validates the barcodes of products written before barcodes
+// were normalized and fills in their format and canonical GTIN-14. Invalid
+// barcodes are kept as they are and reported, and get an empty GTIN so they
+// are not checked again.
+func backfillGTIN(db *gorm.DB) error {
+	var products []models.Product
+	invalid := 0
+	result := db.Unscoped().Where("gtin IS NULL").FindInBatches(&products, 500, func(tx *gorm.DB, batch int) error {
+		for i := range products {
+			columns := map[string]interface{}{"gtin": ""}
+			if products[i].Barcode != "" {
+				code, err := gtin.Parse(products[i].Barcode, "")
+				if err != nil {
+					log.Printf("Product %d has an invalid barcode %q: %v", products[i].ID, products[i].Barcode, err)
+					invalid++
+				} else {
+					columns["barcode"] = code.Digits
+					columns["barcode_format"] = string(code.Format)
+					columns["gtin"] = code.GTIN
+				}
+			}
+			if err := db.Unscoped().Model(&products[i]).UpdateColumns(columns).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return fmt.Errorf("cannot normalize existing barcodes: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Normalized barcodes of %d existing products, %d invalid", result.RowsAffected, invalid)
+	}
+	return nil
+}
@@ -1,68 +1,122 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"product-api/models"
+	"strings"
+	"time"
 
 	"github.com/glebarez/sqlite" // Pure Go SQLite driver for GORM, no CGO needed
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
+// Default locations of the database files
+const (
+	DefaultPath     = "./data/products.db"
+	DefaultJobsPath = "./data/jobs.db"
+)
 
-// InitDB initializes the database connection
-func InitDB() {
-	// Create database directory if it doesn't exist
-	dbDir := "./data"
-	if _, err := os.Stat(dbDir); os.IsNotExist(err) {
-		err = os.MkdirAll(dbDir, 0755)
-		if err != nil {
-			log.Fatalf("Failed to create database directory: %v", err)
-		}
+// Open opens the product database at path, creating the file and its
+// directory if they do not exist, and migrates its schema. GORM logs the
+// statements at logLevel.
+func Open(path string, logLevel logger.LogLevel) (*gorm.DB, error) {
+	db, err := open(path, logLevel)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+	if err := migrateDB(db); err != nil {
+		Close(db)
+		return nil, err
 	}
 
-	// Open the SQLite database file
-	dbPath := filepath.Join(dbDir, "products.db")
+	log.Printf("Connected to SQLite database at %s", path)
+	return db, nil
+}
 
-	// Configure GORM
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), // For more detailed logging
+// OpenJobs opens the job database at path. It is a separate file so that
+// jobs can be queued and report progress while a long import holds the
+// write lock of the product database.
+func OpenJobs(path string, logLevel logger.LogLevel) (*gorm.DB, error) {
+	db, err := open(path, logLevel)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to job database: %w", err)
+	}
+	if err := db.AutoMigrate(&models.Job{}); err != nil {
+		Close(db)
+		return nil, fmt.Errorf("cannot migrate job database: %w", err)
 	}
+	return db, nil
+}
 
-	// Open the database connection with pure Go driver
-	var err error
-	DB, err = gorm.Open(sqlite.Open(dbPath), config)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+// open opens a SQLite database file. WAL lets long reads such as catalog
+// exports run without blocking writers; the busy timeout makes writers wait
+// for each other. Transactions take the write lock when they begin, since a
+// transaction that upgrades from reading to writing fails at once instead of
+// waiting when another writer holds the lock.
+func open(path string, logLevel logger.LogLevel) (*gorm.DB, error) {
+	// Create the database directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
 	}
 
-	// Auto Migrate the schema
-	migrateDB()
+	// Configure GORM. Timestamps are written in UTC since SQLite compares
+	// them as text, which only orders times with the same offset.
+	config := &gorm.Config{
+		Logger:  logger.Default.LogMode(logLevel),
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	}
+
+	dsn := path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	return gorm.Open(sqlite.Open(dsn), config)
+}
+
+// logLevels are the GORM log levels by name
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
 
-	log.Printf("Connected to SQLite database at %s", dbPath)
+// ParseLogLevel returns the GORM log level named silent, error, warn or info
+func ParseLogLevel(name string) (logger.LogLevel, error) {
+	level, ok := logLevels[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown log level %q, want silent, error, warn or info", name)
+	}
+	return level, nil
 }
 
 // migrateDB automatically creates/updates database tables based on models
-func migrateDB() {
-	if err := DB.AutoMigrate(&models.Product{}); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+func migrateDB(db *gorm.DB) error {
+	if err := db.AutoMigrate(&models.Product{}, &models.Synonym{}, &models.SearchRanking{}); err != nil {
+		return fmt.Errorf("cannot migrate database: %w", err)
+	}
+	for _, migrate := range []func(*gorm.DB) error{
+		backfillNormalizedText,
+		migrateSearchIndex,
+		backfillGTIN,
+		migrateUniqueIndexes,
+		migrateUTCTimestamps,
+		migrateTableVersion,
+	} {
+		if err := migrate(db); err != nil {
+			return err
+		}
 	}
 	log.Println("Database migration completed")
+	return nil
 }
 
-// CloseDB closes the database connection
-func CloseDB() {
-	sqlDB, err := DB.DB()
+// Close closes a database connection opened by Open or OpenJobs
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Printf("Error getting DB instance: %v", err)
-		return
+		return err
 	}
-	if err := sqlDB.Close(); err != nil {
-		log.Printf("Error closing database: %v", err)
-		return
-	}
-	log.Println("Database connection closed")
-} 
\ No newline at end of file
+	return sqlDB.Close()
+}
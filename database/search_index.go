@@ -0,0 +1,129 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"product-api/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// searchIndexColumns are the products columns indexed for full-text search.
+// Changing them rebuilds the index on the next start.
+const searchIndexColumns = "name_folded, description_folded, sku"
+
+// searchIndexTriggers keep products_fts in sync with the products table.
+// Only rows that are not soft-deleted are indexed, so the update trigger
+// removes the old entry and re-adds the new one when it is still live. Both
+// steps live in one trigger because SQLite gives no ordering guarantee
+// between separate triggers on the same event.
+// The index is built from the folded text columns maintained by models.Product
+// and the SKU.
+var searchIndexTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS products_fts_ai AFTER INSERT ON products
+	WHEN new.deleted_at IS NULL BEGIN
+		INSERT INTO products_fts(rowid, ` + searchIndexColumns + `)
+		VALUES (new.id, new.name_folded, new.description_folded, new.sku);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS products_fts_ad AFTER DELETE ON products
+	WHEN old.deleted_at IS NULL BEGIN
+		INSERT INTO products_fts(products_fts, rowid, ` + searchIndexColumns + `)
+		VALUES ('delete', old.id, old.name_folded, old.description_folded, old.sku);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS products_fts_au AFTER UPDATE ON products BEGIN
+		INSERT INTO products_fts(products_fts, rowid, ` + searchIndexColumns + `)
+		SELECT 'delete', old.id, old.name_folded, old.description_folded, old.sku
+		WHERE old.deleted_at IS NULL;
+		INSERT INTO products_fts(rowid, ` + searchIndexColumns + `)
+		SELECT new.id, new.name_folded, new.description_folded, new.sku
+		WHERE new.deleted_at IS NULL;
+	END`,
+}
+
+// migrateSearchIndex creates the FTS5 table used for product search and the
+// triggers that keep it up to date. The index is (re)built from existing
+// products when it is first created or when its columns have changed.
+func migrateSearchIndex(db *gorm.DB) error {
+	var schema string
+	if err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'products_fts'").Scan(&schema).Error; err != nil {
+		return fmt.Errorf("cannot inspect search index: %w", err)
+	}
+
+	// Drop an index created by an older version with different columns
+	if schema != "" && !strings.Contains(schema, searchIndexColumns) {
+		for _, stmt := range []string{
+			"DROP TRIGGER IF EXISTS products_fts_ai",
+			"DROP TRIGGER IF EXISTS products_fts_ad",
+			"DROP TRIGGER IF EXISTS products_fts_au",
+			"DROP TABLE products_fts",
+		} {
+			if err := db.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("cannot drop outdated search index: %w", err)
+			}
+		}
+		schema = ""
+	}
+	rebuild := schema == ""
+
+	if err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS products_fts USING fts5(
+		` + searchIndexColumns + `,
+		content='products', content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2'
+	)`).Error; err != nil {
+		return fmt.Errorf("cannot create search index: %w", err)
+	}
+
+	for _, trigger := range searchIndexTriggers {
+		if err := db.Exec(trigger).Error; err != nil {
+			return fmt.Errorf("cannot create search index trigger: %w", err)
+		}
+	}
+
+	if rebuild {
+		if err := RebuildSearchIndex(db); err != nil {
+			return fmt.Errorf("cannot build search index: %w", err)
+		}
+		log.Println("Search index built")
+	}
+	return nil
+}
+
+// RebuildSearchIndex repopulates the search index of db from all live
+// products. Searches keep seeing the old index until the rebuild commits.
+func RebuildSearchIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO products_fts(products_fts) VALUES ('delete-all')").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO products_fts(rowid, ` + searchIndexColumns + `)
+			SELECT id, ` + searchIndexColumns + ` FROM products WHERE deleted_at IS NULL`).Error
+	})
+}
+
+// backfillNormalizedText fills the folded and collation columns of products
+// written before those columns existed
+func backfillNormalizedText(db *gorm.DB) error {
+	var products []models.Product
+	result := db.Unscoped().Where("name_sort_key IS NULL").FindInBatches(&products, 500, func(tx *gorm.DB, batch int) error {
+		for i := range products {
+			products[i].Normalize()
+			err := db.Unscoped().Model(&products[i]).UpdateColumns(map[string]interface{}{
+				"name_folded":        products[i].NameFolded,
+				"description_folded": products[i].DescriptionFolded,
+				"name_sort_key":      products[i].NameSortKey,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return fmt.Errorf("cannot normalize existing products: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Normalized text of %d existing products", result.RowsAffected)
+	}
+	return nil
+}
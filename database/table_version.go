@@ -0,0 +1,41 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// tableVersionTriggers increment the counter in products_version on every
+// change to the products table, including raw updates and purges, so that
+// listings can be revalidated without scanning the table
+var tableVersionTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS products_version_ai AFTER INSERT ON products BEGIN
+		UPDATE products_version SET version = version + 1;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS products_version_au AFTER UPDATE ON products BEGIN
+		UPDATE products_version SET version = version + 1;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS products_version_ad AFTER DELETE ON products BEGIN
+		UPDATE products_version SET version = version + 1;
+	END`,
+}
+
+// migrateTableVersion creates the single-row products_version table read by
+// ProductRepository.TableVersion and the triggers that maintain it
+func migrateTableVersion(db *gorm.DB) error {
+	for _, stmt := range []string{
+		"CREATE TABLE IF NOT EXISTS products_version (version integer NOT NULL)",
+		"INSERT INTO products_version (version) SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM products_version)",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("cannot create table version: %w", err)
+		}
+	}
+	for _, trigger := range tableVersionTriggers {
+		if err := db.Exec(trigger).Error; err != nil {
+			return fmt.Errorf("cannot create table version trigger: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// productTimestamp is the subset of product columns holding timestamps
+type productTimestamp struct {
+	ID        uint
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	DeletedAt *time.Time
+}
+
+// migrateUTCTimestamps rewrites product timestamps written with a local UTC
+// offset in UTC. SQLite compares the stored text, so the keyset scan and the
+// trash purge only order timestamps correctly when they share one offset.
+func migrateUTCTimestamps(db *gorm.DB) error {
+	var rows []productTimestamp
+	local := "created_at NOT LIKE '%+00:00' OR updated_at NOT LIKE '%+00:00' OR deleted_at NOT LIKE '%+00:00'"
+	result := db.Table("products").Select("id", "created_at", "updated_at", "deleted_at").Where(local).FindInBatches(&rows, 500, func(tx *gorm.DB, batch int) error {
+		for _, row := range rows {
+			columns := map[string]interface{}{
+				"created_at": utc(row.CreatedAt),
+				"updated_at": utc(row.UpdatedAt),
+				"deleted_at": utc(row.DeletedAt),
+			}
+			if err := db.Table("products").Where("id = ?", row.ID).UpdateColumns(columns).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return fmt.Errorf("cannot convert product timestamps to UTC: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Converted timestamps of %d existing products to UTC", result.RowsAffected)
+	}
+	return nil
+}
+
+// utc returns t in UTC, or nil for a missing timestamp
+func utc(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// uniqueProductColumns are the products columns that must be unique among
+// live products. Soft-deleted products and empty values are ignored.
+// Barcodes are compared by their canonical GTIN-14.
+var uniqueProductColumns = []string{"sku", "gtin"}
+
+// duplicateGroup is a value used by more than one live product
+type duplicateGroup struct {
+	Value string
+	IDs   string
+}
+
+// migrateUniqueIndexes creates the partial unique indexes on SKU and barcode.
+// Existing duplicates would make the index creation fail, so they are
+// reported first and the index for that column is skipped until they are
+// resolved; the check runs again on every start.
+func migrateUniqueIndexes(db *gorm.DB) error {
+	for _, column := range uniqueProductColumns {
+		index := "idx_products_" + column + "_unique"
+
+		var duplicates []duplicateGroup
+		err := db.Raw(`SELECT ` + column + ` AS value, GROUP_CONCAT(id, ', ') AS ids
+			FROM products
+			WHERE deleted_at IS NULL AND ` + column + ` <> ''
+			GROUP BY ` + column + `
+			HAVING COUNT(*) > 1
+			ORDER BY ` + column).Scan(&duplicates).Error
+		if err != nil {
+			return fmt.Errorf("cannot check duplicate %s values: %w", column, err)
+		}
+
+		if len(duplicates) > 0 {
+			for _, duplicate := range duplicates {
+				log.Printf("Duplicate %s %q used by products %s", column, duplicate.Value, duplicate.IDs)
+			}
+			log.Printf("Skipping unique index %s until %d duplicate %s values are resolved", index, len(duplicates), column)
+			continue
+		}
+
+		err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS ` + index + ` ON products(` + column + `)
+			WHERE deleted_at IS NULL AND ` + column + ` <> ''`).Error
+		if err != nil {
+			return fmt.Errorf("cannot create unique index %s: %w", index, err)
+		}
+	}
+	return nil
+}
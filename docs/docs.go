@@ -24,30 +24,37 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/products": {
+        "/admin/search/ranking": {
             "get": {
-                "description": "Retrieve all products from the system",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retrieve the boosts applied to product search results",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Get all products",
+                "summary": "Get search ranking rules",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Product"
-                            }
+                            "$ref": "#/definitions/models.SearchRanking"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Add a new product to the system",
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the boosts applied to product search results",
                 "consumes": [
                     "application/json"
                 ],
@@ -55,78 +62,127 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Create a new product",
+                "summary": "Update search ranking rules",
                 "parameters": [
                     {
-                        "description": "Product to add",
-                        "name": "product",
+                        "description": "Ranking rules",
+                        "name": "ranking",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProductCreateDTO"
+                            "$ref": "#/definitions/models.SearchRanking"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.SearchRanking"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/products/{id}": {
+        "/admin/search/synonyms": {
             "get": {
-                "description": "Retrieve a product by its ID",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retrieve the synonym dictionary used by product search",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Get a product by ID",
+                "summary": "List search synonyms",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Synonym"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Add a term whose synonyms are searched as well. Terms are stored case and accent folded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create a search synonym",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Synonym to add",
+                        "name": "synonym",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SynonymDTO"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Synonym"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/search/synonyms/{id}": {
             "put": {
-                "description": "Update an existing product",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the term and synonyms of an existing entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -134,24 +190,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Update a product",
+                "summary": "Update a search synonym",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Synonym ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated product data",
-                        "name": "product",
+                        "description": "Updated synonym",
+                        "name": "synonym",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProductUpdateDTO"
+                            "$ref": "#/definitions/models.SynonymDTO"
                         }
                     }
                 ],
@@ -159,42 +215,47 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Synonym"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Remove a product from the system",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Remove an entry from the synonym dictionary",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Delete a product",
+                "summary": "Delete a search synonym",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Synonym ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -213,116 +274,2429 @@ const docTemplate = `{
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Product": {
-            "type": "object",
-            "properties": {
-                "barcode": {
-                    "description": "Barcode of the product",
-                    "type": "string"
-                },
-                "created_at": {
-                    "description": "Timestamp of creation",
-                    "type": "string"
-                },
-                "description": {
-                    "description": "Detailed description",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "Unique identifier",
-                    "type": "integer"
-                },
-                "name": {
-                    "description": "Name of the product",
-                    "type": "string"
-                },
-                "price": {
-                    "description": "Price of the product",
-                    "type": "number"
-                },
-                "quantity": {
-                    "description": "Quantity of the product",
-                    "type": "integer"
-                },
-                "sku": {
-                    "description": "Stock Keeping Unit",
-                    "type": "string"
-                },
-                "updated_at": {
-                    "description": "Timestamp of last update",
-                    "type": "string"
-                }
-            }
         },
-        "models.ProductCreateDTO": {
-            "type": "object",
-            "properties": {
-                "barcode": {
-                    "description": "Barcode of the product",
-                    "type": "string"
-                },
-                "description": {
-                    "description": "Detailed description",
-                    "type": "string"
-                },
-                "name": {
-                    "description": "Name of the product",
-                    "type": "string"
-                },
-                "price": {
-                    "description": "Price of the product",
-                    "type": "number"
-                },
-                "quantity": {
-                    "description": "Quantity of the product",
-                    "type": "integer"
-                },
-                "sku": {
-                    "description": "Stock Keeping Unit",
-                    "type": "string"
+        "/jobs/exports": {
+            "post": {
+                "description": "Queue an export with the same query parameters as GET /products/export.\nThe exported file becomes the job result once it finishes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Start an export job",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "File format (default csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns to export, in order (default all)",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by partial product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
                 }
             }
         },
-        "models.ProductUpdateDTO": {
-            "type": "object",
-            "properties": {
-                "barcode": {
-                    "description": "Barcode of the product",
-                    "type": "string"
-                },
-                "description": {
-                    "description": "Detailed description",
-                    "type": "string"
-                },
-                "name": {
-                    "description": "Name of the product",
-                    "type": "string"
-                },
-                "price": {
-                    "description": "Price of the product",
-                    "type": "number"
-                },
-                "quantity": {
-                    "description": "Quantity of the product",
-                    "type": "integer"
-                },
-                "sku": {
-                    "description": "Stock Keeping Unit",
-                    "type": "string"
-                }
+        "/jobs/imports": {
+            "post": {
+                "description": "Queue an import with the same form fields as POST /products/import.\nThe import report becomes the job result once it finishes.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Start a CSV import job",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file with a header row",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object mapping fields to column headers",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Column delimiter (default ,)",
+                        "name": "delimiter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without writing",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/price-changes": {
+            "post": {
+                "description": "Change the price of every product matching the listing filters, either by a\npercentage or by a fixed amount. Prices are rounded to cents and products whose\nprice would drop to zero or below are skipped. The counts become the job result.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Start a bulk price change job",
+                "parameters": [
+                    {
+                        "description": "Exactly one of percent and amount",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceChange"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by partial product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/reindex": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Recompute the normalized text and GTIN of every product and rebuild the\nfull-text and autocomplete indexes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Start a search reindex job",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Retrieve the status and progress of a job. Once it has succeeded,\nresult_url points to its result if it has one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "description": "Cancel a queued or running job. A queued job is cancelled at once; a running\njob stops at its next progress update and then has the cancelled status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancel a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Job already finished",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/result": {
+            "get": {
+                "description": "Download the file produced by a succeeded job",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Download a job result",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Job has not succeeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Retrieve a page of products with optional filters and sorting.\nSet paginate=cursor (or pass a cursor) to page by (updated_at, id) instead;\nthe response is then a models.ProductCursorResponse.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items (alternative to page_size)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (alternative to page)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by partial product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (e.g. price,-created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "description": "Set to cursor to start a keyset scan",
+                        "name": "paginate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page, valid for 24 hours with the same filters",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated facets to count over all matching products (price, stock)",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ascending price bucket boundaries for the price facet (default 50,100,250,500,1000)",
+                        "name": "price_buckets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously returned listing",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductListResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak validator of the listing"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Listing has not changed"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new product to the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create a new product",
+                "parameters": [
+                    {
+                        "description": "Product to add",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductCreateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU or barcode already used, conflicting_id names the product",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/batch": {
+            "post": {
+                "description": "Apply a list of create, update and delete operations in order. Each operation\nis validated like its single-product endpoint; version works like If-Match.\nIn atomic mode (default) all operations run in one transaction. Invalid operations\nreject the batch with validation_failed; if an operation fails, nothing is applied\nand the response is a batch_failed problem whose results hold the failing operation.\nIn per_item mode every operation is applied on its own and the response is\nalways 200 with a result per operation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create, update and delete products in bulk",
+                "parameters": [
+                    {
+                        "description": "Batch of operations",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/by-barcode/{code}": {
+            "get": {
+                "description": "Retrieve the product with the given barcode. The code may be given as\nEAN-8, UPC-E, UPC-A, EAN-13 or GTIN-14 and matches every equivalent form.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get a product by barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/by-sku/{sku}": {
+            "get": {
+                "description": "Retrieve the product with the given Stock Keeping Unit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get a product by SKU",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create the product with the given SKU, or replace all fields of the existing one.\nA soft-deleted product with the SKU is restored with the new values instead of\ncreating a duplicate. Repeating the same request does not change the product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create or replace a product by SKU",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product data; sku may be omitted but must match the path",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductUpdateDTO"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Existing product replaced",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Product created or restored",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Barcode already used, conflicting_id names the product",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/export": {
+            "get": {
+                "description": "Download all products matching the listing filters as CSV, NDJSON or XLSX.\nRows are streamed from the database as they are read, so the whole catalog\ncan be exported. Pagination parameters are ignored.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Export products",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "File format (default csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns to export, in order (default all): id, name, description, price, quantity, sku, barcode, barcode_format, gtin, version, created_at, updated_at",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by partial product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (e.g. price,-created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/import": {
+            "post": {
+                "description": "Upsert products by SKU from an uploaded CSV file. Each row is validated like\nPOST /products and rejected rows are reported with their line number and reason.\nColumns are matched to fields by name unless a mapping is given. Existing products\nkeep the fields the file has no column for. With dry_run the report is produced\nwithout writing anything.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Import products from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file with a header row",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object mapping fields to column headers, e.g. {\\",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Column delimiter (default ,)",
+                        "name": "delimiter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without writing",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Full-text search over product names and descriptions, ranked by relevance.\nname_highlight and description_snippet are HTML: the product text is escaped and matched terms are wrapped in \u003cmark\u003e tags.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Search products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items (alternative to page_size)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (alternative to page)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by partial product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort fields overriding relevance order (e.g. price,-created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated facets to count over all matching products (price, stock)",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ascending price bucket boundaries for the price facet (default 50,100,250,500,1000)",
+                        "name": "price_buckets",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/suggest": {
+            "get": {
+                "description": "Suggest product names and SKUs starting with the given prefix, tolerating typos in names",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Suggest products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text typed so far",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of suggestions (default 10, max 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductSuggestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/trash": {
+            "get": {
+                "description": "Retrieve a page of soft-deleted products, most recently deleted first.\nAccepts the same filters and sort fields as the product listing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List trashed products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items (alternative to page_size)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (alternative to page)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by partial product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (e.g. price,-created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductTrashResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Retrieve a product by its ID. The ETag header identifies the\nreturned version and can be sent as If-Match when modifying it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get a product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously returned version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a previously returned version",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last update"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Product has not changed"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated product data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductUpdateDTO"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU or barcode already used, conflicting_id names the product",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Move a product to the trash, from where it can be restored. With permanent=true\nthe product is removed for good, whether it is live or already in the trash;\nthis requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete permanently instead of moving to the trash (admin only)",
+                        "name": "permanent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) to a product.\nThe patch is applied to the fields of models.ProductUpdateDTO, the result is\nvalidated and only the changed columns are written.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Partially update a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or array of JSON Patch operations",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being patched",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the patched product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Failed test operation, or SKU or barcode already used",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/restore": {
+            "post": {
+                "description": "Move a soft-deleted product out of the trash",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Restore a trashed product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the restored product"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU or barcode now used by another product, conflicting_id names it",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.FacetBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number of matching products",
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "Lower bound (inclusive) for range buckets",
+                    "type": "number"
+                },
+                "key": {
+                    "description": "Bucket label, e.g. \"50-100\" or \"in_stock\"",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Upper bound (exclusive) for range buckets",
+                    "type": "number"
+                }
+            }
+        },
+        "models.Facets": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "array",
+                "items": {
+                    "$ref": "#/definitions/models.FacetBucket"
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Number of times a worker started the job",
+                    "type": "integer"
+                },
+                "cancel_requested": {
+                    "description": "Cancellation was asked for while running",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "Time the job was queued",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why the job failed",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "Time the job finished",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Units of work done, e.g. rows",
+                    "type": "integer"
+                },
+                "result_url": {
+                    "description": "Where to download the result, once available",
+                    "type": "string"
+                },
+                "started_at": {
+                    "description": "Time the last attempt started",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "queued, running, succeeded, failed or cancelled",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Units of work in total, 0 while unknown",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Kind of work, e.g. product_import",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Time of the last change",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PageMeta": {
+            "type": "object",
+            "properties": {
+                "offset": {
+                    "description": "Number of items skipped",
+                    "type": "integer"
+                },
+                "page": {
+                    "description": "Current page number (1-based)",
+                    "type": "integer"
+                },
+                "page_size": {
+                    "description": "Number of items per page",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total number of matching products",
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "description": "Total number of pages",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PriceChange": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Fixed amount added to every price",
+                    "type": "number"
+                },
+                "percent": {
+                    "description": "Relative change, e.g. 10 raises prices by 10%",
+                    "type": "number"
+                }
+            }
+        },
+        "models.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable machine-readable error code",
+                    "type": "string"
+                },
+                "conflicting_id": {
+                    "description": "Resource holding the value, for duplicate_value",
+                    "type": "integer"
+                },
+                "detail": {
+                    "description": "Explanation of this occurrence",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Invalid fields by JSON path, for validation_failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/validation.Errors"
+                        }
+                    ]
+                },
+                "instance": {
+                    "description": "Path of the request",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "ID of the request, also sent as X-Request-ID",
+                    "type": "string"
+                },
+                "results": {
+                    "description": "Results are the failed operations of a rejected or rolled back batch",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductBatchResult"
+                    }
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Short summary of the error code",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "URI identifying the error code",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "description": "Barcode of the product",
+                    "type": "string"
+                },
+                "barcode_format": {
+                    "description": "Format of Barcode, e.g. EAN-13 or UPC-A",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Timestamp of creation",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Detailed description",
+                    "type": "string"
+                },
+                "gtin": {
+                    "description": "Barcode as canonical GTIN-14, maintained by BeforeSave",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name of the product",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price of the product",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Quantity of the product",
+                    "type": "integer"
+                },
+                "sku": {
+                    "description": "Stock Keeping Unit",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Timestamp of last update",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every update, used for the ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductBatchOperation": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "id": {
+                    "description": "Product to update or delete",
+                    "type": "integer"
+                },
+                "op": {
+                    "description": "create, update or delete",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "product": {
+                    "description": "Product data for create and update",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ProductUpdateDTO"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "Optional expected version, like If-Match",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductBatchRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "description": "atomic (default) or per_item",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "per_item"
+                    ]
+                },
+                "operations": {
+                    "description": "Operations in the order they are applied, at most MaxBatchOperations",
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "$ref": "#/definitions/models.ProductBatchOperation"
+                    }
+                }
+            }
+        },
+        "models.ProductBatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Number of failed operations",
+                    "type": "integer"
+                },
+                "mode": {
+                    "description": "Mode the batch ran in",
+                    "type": "string"
+                },
+                "results": {
+                    "description": "Per operation results",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductBatchResult"
+                    }
+                },
+                "succeeded": {
+                    "description": "Number of applied operations",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductBatchResult": {
+            "type": "object",
+            "properties": {
+                "conflicting_id": {
+                    "description": "Product that already uses a unique value",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Why the operation failed",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Invalid fields of an invalid operation",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/validation.Errors"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "Product ID",
+                    "type": "integer"
+                },
+                "index": {
+                    "description": "Position of the operation in the request",
+                    "type": "integer"
+                },
+                "op": {
+                    "description": "Operation kind",
+                    "type": "string"
+                },
+                "product": {
+                    "description": "Created or updated product",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "HTTP status the operation would have on its own",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductCreateDTO": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "barcode": {
+                    "description": "Barcode of the product",
+                    "type": "string"
+                },
+                "barcode_format": {
+                    "description": "Optional, detected from the barcode when empty",
+                    "type": "string",
+                    "enum": [
+                        "EAN-8",
+                        "UPC-E",
+                        "UPC-A",
+                        "EAN-13",
+                        "GTIN-14",
+                        "ITF-14"
+                    ]
+                },
+                "description": {
+                    "description": "Detailed description",
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "name": {
+                    "description": "Name of the product",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "price": {
+                    "description": "Price of the product",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Quantity of the product",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "sku": {
+                    "description": "Stock Keeping Unit, without spaces",
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "models.ProductImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Number of created or revived products",
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "description": "Whether the import was only simulated",
+                    "type": "boolean"
+                },
+                "rejected": {
+                    "description": "Number of rejected rows",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "Per row results in file order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductImportRow"
+                    }
+                },
+                "total": {
+                    "description": "Number of rows read",
+                    "type": "integer"
+                },
+                "untouched_fields": {
+                    "description": "UntouchedFields have no column in the file; updated products keep their stored values",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated": {
+                    "description": "Number of replaced products",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductImportRow": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "created, updated or rejected",
+                    "type": "string"
+                },
+                "conflicting_id": {
+                    "description": "Product that already uses the row's barcode",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID of the product, not set for creates in a dry run",
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "Line of the row in the file, the header is line 1",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Why the row was rejected",
+                    "type": "string"
+                },
+                "sku": {
+                    "description": "SKU of the row",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductListResponse": {
+            "type": "object",
+            "properties": {
+                "facets": {
+                    "description": "Requested facet counts over all matching products",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Facets"
+                        }
+                    ]
+                },
+                "items": {
+                    "description": "Products on the current page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
+                },
+                "meta": {
+                    "description": "Paging metadata",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PageMeta"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.ProductSearchHit": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "description": "Barcode of the product",
+                    "type": "string"
+                },
+                "barcode_format": {
+                    "description": "Format of Barcode, e.g. EAN-13 or UPC-A",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Timestamp of creation",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Detailed description",
+                    "type": "string"
+                },
+                "description_snippet": {
+                    "description": "HTML-escaped excerpt of the description around the matches, marked like name_highlight",
+                    "type": "string"
+                },
+                "gtin": {
+                    "description": "Barcode as canonical GTIN-14, maintained by BeforeSave",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name of the product",
+                    "type": "string"
+                },
+                "name_highlight": {
+                    "description": "HTML-escaped name with matched terms wrapped in \u003cmark\u003e",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price of the product",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Quantity of the product",
+                    "type": "integer"
+                },
+                "rank": {
+                    "description": "BM25 relevance, lower is better",
+                    "type": "number"
+                },
+                "score": {
+                    "description": "Relevance after ranking boosts, higher is better",
+                    "type": "number"
+                },
+                "sku": {
+                    "description": "Stock Keeping Unit",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Timestamp of last update",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every update, used for the ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductSearchResponse": {
+            "type": "object",
+            "properties": {
+                "facets": {
+                    "description": "Requested facet counts over all matching products",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Facets"
+                        }
+                    ]
+                },
+                "items": {
+                    "description": "Matching products ordered by relevance",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductSearchHit"
+                    }
+                },
+                "meta": {
+                    "description": "Paging metadata",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PageMeta"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.ProductSuggestResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Suggestions ordered by score",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductSuggestion"
+                    }
+                }
+            }
+        },
+        "models.ProductSuggestion": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Product ID",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Product name",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Match quality, higher is better",
+                    "type": "number"
+                },
+                "sku": {
+                    "description": "Stock Keeping Unit",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductTrashResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Trashed products, most recently deleted first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashedProduct"
+                    }
+                },
+                "meta": {
+                    "description": "Paging metadata",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PageMeta"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.ProductUpdateDTO": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "barcode": {
+                    "description": "Barcode of the product",
+                    "type": "string"
+                },
+                "barcode_format": {
+                    "description": "Optional, detected from the barcode when empty",
+                    "type": "string",
+                    "enum": [
+                        "EAN-8",
+                        "UPC-E",
+                        "UPC-A",
+                        "EAN-13",
+                        "GTIN-14",
+                        "ITF-14"
+                    ]
+                },
+                "description": {
+                    "description": "Detailed description",
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "name": {
+                    "description": "Name of the product",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "price": {
+                    "description": "Price of the product",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Quantity of the product",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "sku": {
+                    "description": "Stock Keeping Unit, without spaces",
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "models.SearchRanking": {
+            "type": "object",
+            "properties": {
+                "exact_sku_boost": {
+                    "description": "Boost when the query equals the SKU",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "in_stock_boost": {
+                    "description": "Boost for products with Quantity \u003e 0",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "recency_boost": {
+                    "description": "Boost for recently created products",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "recency_half_life_days": {
+                    "description": "Age in days at which the recency boost is halved",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "description": "Timestamp of last update",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Synonym": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Timestamp of creation",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "integer"
+                },
+                "synonyms": {
+                    "description": "Words or phrases searched in addition",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "term": {
+                    "description": "Word typed by the user",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Timestamp of last update",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SynonymDTO": {
+            "type": "object",
+            "required": [
+                "synonyms",
+                "term"
+            ],
+            "properties": {
+                "synonyms": {
+                    "description": "Words or phrases searched in addition",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "term": {
+                    "description": "Single word typed by the user",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrashedProduct": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "description": "Barcode of the product",
+                    "type": "string"
+                },
+                "barcode_format": {
+                    "description": "Format of Barcode, e.g. EAN-13 or UPC-A",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Timestamp of creation",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "When the product was moved to the trash",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Detailed description",
+                    "type": "string"
+                },
+                "gtin": {
+                    "description": "Barcode as canonical GTIN-14, maintained by BeforeSave",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name of the product",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price of the product",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Quantity of the product",
+                    "type": "integer"
+                },
+                "sku": {
+                    "description": "Stock Keeping Unit",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Timestamp of last update",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every update, used for the ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "validation.Errors": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/validation.FieldError"
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Name of the failed rule, e.g. required or gt",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Human readable explanation",
+                    "type": "string"
+                }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin token in the form \"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,214 @@
+// Package exporter writes product catalogs as CSV, NDJSON or XLSX files.
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"product-api/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+	XLSX   = "xlsx"
+)
+
+// contentTypes is the media type of each format
+var contentTypes = map[string]string{
+	CSV:    "text/csv; charset=utf-8",
+	NDJSON: "application/x-ndjson",
+	XLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Columns are the product fields that can be exported, by their JSON names,
+// in the order used when no columns are selected
+var Columns = []string{
+	"id", "name", "description", "price", "quantity", "sku", "barcode",
+	"barcode_format", "gtin", "version", "created_at", "updated_at",
+}
+
+// IsFormat reports whether format is a supported export format
+func IsFormat(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// ContentType returns the media type of a format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// ParseColumns reads a comma separated list of columns. An empty list
+// selects all Columns.
+func ParseColumns(text string) ([]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return Columns, nil
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, column := range strings.Split(text, ",") {
+		column = strings.TrimSpace(column)
+		if !isColumn(column) {
+			return nil, fmt.Errorf("invalid column %q, valid columns are %s", column, strings.Join(Columns, ", "))
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("column %q is selected more than once", column)
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// Writer writes products to a file one at a time
+type Writer interface {
+	// Write adds a product to the file
+	Write(product models.Product) error
+	// Close completes the file. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a Writer that writes the given columns of each product
+// to w in the given format. Header rows are written immediately.
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case NDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// csvWriter writes a header row followed by one row per product
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	if err := writer.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *csvWriter) Write(product models.Product) error {
+	for i, column := range w.columns {
+		w.record[i] = formatValue(value(product, column))
+	}
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// ndjsonWriter writes one JSON object per line, keeping the column order
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (w *ndjsonWriter) Write(product models.Product) error {
+	w.w.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		data, err := json.Marshal(value(product, column))
+		if err != nil {
+			return err
+		}
+		w.w.Write(key)
+		w.w.WriteByte(':')
+		w.w.Write(data)
+	}
+	_, err := w.w.WriteString("}\n")
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.w.Flush()
+}
+
+// value returns a column of a product. Numbers keep their type so formats
+// that distinguish them can do so; timestamps are RFC 3339 strings and
+// missing timestamps are nil.
+func value(product models.Product, column string) interface{} {
+	switch column {
+	case "id":
+		return product.ID
+	case "name":
+		return product.Name
+	case "description":
+		return product.Description
+	case "price":
+		return product.Price
+	case "quantity":
+		return product.Quantity
+	case "sku":
+		return product.SKU
+	case "barcode":
+		return product.Barcode
+	case "barcode_format":
+		return product.BarcodeFormat
+	case "gtin":
+		return product.GTIN
+	case "version":
+		return product.Version
+	case "created_at":
+		return formatTime(product.CreatedAt)
+	case "updated_at":
+		return formatTime(product.UpdatedAt)
+	default:
+		return nil
+	}
+}
+
+// formatTime formats a timestamp as RFC 3339 in UTC
+func formatTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatValue converts a column value to text
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// isColumn reports whether name is one of Columns
+func isColumn(name string) bool {
+	for _, column := range Columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
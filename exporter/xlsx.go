@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"product-api/models"
+)
+
+// Static parts of a workbook with a single worksheet. The worksheet uses
+// inline strings, so no shared strings table or styles are needed.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Products" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter streams the worksheet as the last entry of the zip archive,
+// so rows are written as they arrive instead of being held in memory
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []string
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxWriter{zip: archive, sheet: bufio.NewWriter(entry), columns: columns}
+	writer.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	// Header row
+	writer.sheet.WriteString("<row>")
+	for _, column := range columns {
+		writer.writeString(column)
+	}
+	writer.sheet.WriteString("</row>")
+	return writer, nil
+}
+
+func (w *xlsxWriter) Write(product models.Product) error {
+	w.sheet.WriteString("<row>")
+	for _, column := range w.columns {
+		switch v := value(product, column).(type) {
+		case nil:
+			w.sheet.WriteString("<c/>")
+		case string:
+			w.writeString(v)
+		default:
+			w.sheet.WriteString("<c><v>" + formatValue(v) + "</v></c>")
+		}
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+// writeString writes an inline string cell. Text is escaped, and characters
+// not allowed in XML are replaced.
+func (w *xlsxWriter) writeString(text string) {
+	w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(w.sheet, []byte(text))
+	w.sheet.WriteString("</t></is></c>")
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString("</sheetData></worksheet>")
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
@@ -0,0 +1,158 @@
+// Package gtin validates GS1 product barcodes (EAN-8, UPC-E, UPC-A, EAN-13,
+// GTIN-14 and ITF-14) and converts them to the canonical 14 digit GTIN.
+package gtin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format is a barcode symbology
+type Format string
+
+// Supported formats
+const (
+	EAN8   Format = "EAN-8"
+	UPCE   Format = "UPC-E"
+	UPCA   Format = "UPC-A"
+	EAN13  Format = "EAN-13"
+	GTIN14 Format = "GTIN-14"
+	ITF14  Format = "ITF-14"
+)
+
+// formatLengths is the number of digits of each format
+var formatLengths = map[Format]int{
+	EAN8:   8,
+	UPCE:   8,
+	UPCA:   12,
+	EAN13:  13,
+	GTIN14: 14,
+	ITF14:  14,
+}
+
+// Errors returned by Parse
+var (
+	ErrInvalidCharacters = errors.New("barcode must contain only digits")
+	ErrInvalidLength     = errors.New("barcode must have 8, 12, 13 or 14 digits")
+	ErrInvalidCheckDigit = errors.New("barcode check digit is invalid")
+	ErrUnknownFormat     = errors.New("unknown barcode format")
+)
+
+// Code is a validated barcode
+type Code struct {
+	Digits string // The barcode digits in its own format
+	Format Format // The format of Digits
+	GTIN   string // The barcode as a 14 digit GTIN
+}
+
+// Parse validates a barcode and returns it with its canonical GTIN-14.
+// Spaces and hyphens are ignored. With an empty format the format is
+// detected from the length: 8 digits are EAN-8 unless only the UPC-E check
+// digit is valid, and 14 digits are GTIN-14. Pass UPCE or ITF14 to resolve
+// those ambiguities explicitly.
+func Parse(code string, format Format) (Code, error) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(code)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Code{}, ErrInvalidCharacters
+		}
+	}
+
+	if format == "" {
+		format = detect(digits)
+		if format == "" {
+			return Code{}, ErrInvalidLength
+		}
+	}
+	length, ok := formatLengths[format]
+	if !ok {
+		return Code{}, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+	if len(digits) != length {
+		return Code{}, fmt.Errorf("%s barcode must have %d digits", format, length)
+	}
+
+	full := digits
+	if format == UPCE {
+		var err error
+		if full, err = expandUPCE(digits); err != nil {
+			return Code{}, err
+		}
+	}
+	if !validCheckDigit(full) {
+		return Code{}, ErrInvalidCheckDigit
+	}
+
+	return Code{
+		Digits: digits,
+		Format: format,
+		GTIN:   strings.Repeat("0", 14-len(full)) + full,
+	}, nil
+}
+
+// ToGTIN14 returns the canonical GTIN-14 of a barcode in any supported format
+func ToGTIN14(code string) (string, error) {
+	parsed, err := Parse(code, "")
+	if err != nil {
+		return "", err
+	}
+	return parsed.GTIN, nil
+}
+
+// detect picks the format of a barcode from its digits
+func detect(digits string) Format {
+	switch len(digits) {
+	case 8:
+		if !validCheckDigit(digits) {
+			if full, err := expandUPCE(digits); err == nil && validCheckDigit(full) {
+				return UPCE
+			}
+		}
+		return EAN8
+	case 12:
+		return UPCA
+	case 13:
+		return EAN13
+	case 14:
+		return GTIN14
+	default:
+		return ""
+	}
+}
+
+// validCheckDigit verifies the GS1 mod 10 check digit, the last digit of code.
+// Weights 3 and 1 alternate starting with 3 next to the check digit.
+func validCheckDigit(code string) bool {
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+// expandUPCE converts an 8 digit UPC-E code (number system, six data digits
+// and check digit) to the equivalent 12 digit UPC-A code
+func expandUPCE(code string) (string, error) {
+	if code[0] != '0' && code[0] != '1' {
+		return "", errors.New("UPC-E barcode must start with 0 or 1")
+	}
+	ns, d, check := code[:1], code[1:7], code[7:]
+
+	var body string
+	switch d[5] {
+	case '0', '1', '2':
+		body = d[0:2] + d[5:6] + "0000" + d[2:5]
+	case '3':
+		body = d[0:3] + "00000" + d[3:5]
+	case '4':
+		body = d[0:4] + "00000" + d[4:5]
+	default:
+		body = d[0:5] + "0000" + d[5:6]
+	}
+	return ns + body + check, nil
+}
@@ -0,0 +1,79 @@
+package gtin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		format Format
+		want   Code
+	}{
+		{"EAN-8", "96385074", "", Code{"96385074", EAN8, "00000096385074"}},
+		{"UPC-E detected", "04252614", "", Code{"04252614", UPCE, "00042100005264"}},
+		{"UPC-E ending in 3", "01234531", UPCE, Code{"01234531", UPCE, "00012300000451"}},
+		{"UPC-A", "036000291452", "", Code{"036000291452", UPCA, "00036000291452"}},
+		{"EAN-13", "5901234123457", "", Code{"5901234123457", EAN13, "05901234123457"}},
+		{"EAN-13 with separators", "590-1234 123457", "", Code{"5901234123457", EAN13, "05901234123457"}},
+		{"GTIN-14", "10012345678902", "", Code{"10012345678902", GTIN14, "10012345678902"}},
+		{"ITF-14", "10012345678902", ITF14, Code{"10012345678902", ITF14, "10012345678902"}},
+		// 01234565 has a valid check digit both as EAN-8 and as UPC-E
+		{"EAN-8 and UPC-E detected as EAN-8", "01234565", "", Code{"01234565", EAN8, "00000001234565"}},
+		{"EAN-8 and UPC-E read as UPC-E", "01234565", UPCE, Code{"01234565", UPCE, "00012345000065"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.code, tt.format)
+			if err != nil {
+				t.Fatalf("Parse(%q, %q): %v", tt.code, tt.format, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %q) = %+v, want %+v", tt.code, tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		format Format
+		want   error // nil when only the message is specific to the format
+	}{
+		{"EAN-8 check digit", "96385075", "", ErrInvalidCheckDigit},
+		{"UPC-E check digit", "04252615", UPCE, ErrInvalidCheckDigit},
+		{"UPC-A check digit", "036000291453", "", ErrInvalidCheckDigit},
+		{"EAN-13 check digit", "5901234123458", "", ErrInvalidCheckDigit},
+		{"GTIN-14 check digit", "10012345678903", "", ErrInvalidCheckDigit},
+		{"letters", "59012341234A7", "", ErrInvalidCharacters},
+		{"too short", "1234567", "", ErrInvalidLength},
+		{"between lengths", "12345678901", "", ErrInvalidLength},
+		{"unknown format", "5901234123457", "QR", ErrUnknownFormat},
+		{"length of another format", "5901234123457", UPCA, nil},
+		{"UPC-E number system", "24252614", UPCE, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.code, tt.format)
+			if err == nil {
+				t.Fatalf("Parse(%q, %q) = %+v, want an error", tt.code, tt.format, got)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q, %q) error = %v, want %v", tt.code, tt.format, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToGTIN14(t *testing.T) {
+	// The same product as UPC-E, UPC-A and EAN-13 has one GTIN
+	for _, code := range []string{"04252614", "042100005264", "0042100005264"} {
+		if got, err := ToGTIN14(code); err != nil || got != "00042100005264" {
+			t.Errorf("ToGTIN14(%q) = %q, %v, want 00042100005264", code, got, err)
+		}
+	}
+}
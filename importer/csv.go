@@ -0,0 +1,253 @@
+// Package importer reads product catalogs supplied as CSV files.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"product-api/models"
+	"strconv"
+	"strings"
+)
+
+// Fields are the ProductCreateDTO fields that can be read from a CSV column,
+// by their JSON names
+var Fields = []string{"name", "description", "price", "quantity", "sku", "barcode", "barcode_format"}
+
+// requiredFields must have a column in every import
+var requiredFields = []string{"name", "price", "sku"}
+
+// Mapping maps product fields, by their JSON names, to CSV column headers.
+// Fields without an entry are read from the column with the same name as the field.
+type Mapping map[string]string
+
+// ParseMapping decodes a JSON object mapping field names to column headers
+// and checks that every key is a known field
+func ParseMapping(text string) (Mapping, error) {
+	mapping := Mapping{}
+	if strings.TrimSpace(text) == "" {
+		return mapping, nil
+	}
+	if err := json.Unmarshal([]byte(text), &mapping); err != nil {
+		return nil, errors.New("mapping must be a JSON object of field names to column headers")
+	}
+	for field := range mapping {
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field %q in mapping, valid fields are %s", field, strings.Join(Fields, ", "))
+		}
+	}
+	return mapping, nil
+}
+
+// Row is one CSV record converted to a product
+type Row struct {
+	Line    int                     // Line number of the record in the file, starting at 1 for the header
+	Product models.ProductCreateDTO // Values read from the record
+	Err     string                  // Why the record could not be converted, empty on success
+}
+
+// ReadCSV reads a CSV file whose first record is the header. Columns are
+// matched to fields through the mapping, or by field name ignoring case.
+// Besides the rows it returns the fields that have a column, in the order of
+// Fields; barcode_format only counts along with barcode. Records whose values
+// cannot be converted are returned with Err set; an error is only returned
+// when the file or its header is unusable.
+func ReadCSV(r io.Reader, mapping Mapping, delimiter rune) ([]Row, []string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns, err := resolveColumns(header, mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("invalid CSV on line %d: %v", parseErr.Line, parseErr.Err)
+			}
+			return nil, nil, err
+		}
+		if isBlank(record) {
+			continue
+		}
+		// FieldPos is only defined after a record was read successfully
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, convertRecord(line, record, columns))
+	}
+
+	var fields []string
+	for _, field := range Fields {
+		if columns[field] >= 0 && (field != "barcode_format" || columns["barcode"] >= 0) {
+			fields = append(fields, field)
+		}
+	}
+	return rows, fields, nil
+}
+
+// resolveColumns finds the column index of every field. Fields without a
+// column get -1; required fields must have one.
+func resolveColumns(header []string, mapping Mapping) (map[string]int, error) {
+	indexes := make(map[string]int, len(header))
+	for i, name := range header {
+		indexes[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int, len(Fields))
+	for _, field := range Fields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		index, ok := indexes[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("column %q mapped to %s is not in the CSV header", name, field)
+			}
+			index = -1
+		}
+		columns[field] = index
+	}
+
+	for _, field := range requiredFields {
+		if columns[field] < 0 {
+			return nil, fmt.Errorf("CSV has no column for %s, add one or map it", field)
+		}
+	}
+	return columns, nil
+}
+
+// convertRecord reads the mapped values of a record into a product
+func convertRecord(line int, record []string, columns map[string]int) Row {
+	value := func(field string) string {
+		index := columns[field]
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	row := Row{Line: line}
+	row.Product = models.ProductCreateDTO{
+		Name:          value("name"),
+		Description:   value("description"),
+		SKU:           value("sku"),
+		Barcode:       value("barcode"),
+		BarcodeFormat: value("barcode_format"),
+	}
+
+	price, err := parsePrice(value("price"))
+	if err != nil {
+		row.Err = fmt.Sprintf("Invalid price %q: %v", value("price"), err)
+		return row
+	}
+	row.Product.Price = price
+
+	if quantity := value("quantity"); quantity != "" {
+		if row.Product.Quantity, err = strconv.Atoi(quantity); err != nil {
+			row.Err = fmt.Sprintf("Invalid quantity %q", quantity)
+			return row
+		}
+	}
+	return row
+}
+
+// Errors returned by parsePrice
+var (
+	errPriceFormat    = errors.New("not a number")
+	errAmbiguousPrice = errors.New("a separator followed by three digits is ambiguous, write 1250 or 1250,00")
+)
+
+// parsePrice parses a price written with a decimal point or, as is common in
+// Turkish spreadsheets, a decimal comma, optionally with the other character
+// grouping thousands: 1234.5, 1234,5, 1,234.50 and 1.234,50 are all accepted.
+// When both characters occur the last one is the decimal separator, and a
+// character repeated in the whole part only groups thousands. A lone
+// separator followed by three digits, as in 1.250, could mean either and is
+// rejected rather than guessed.
+func parsePrice(text string) (float64, error) {
+	if text == "" {
+		return 0, nil
+	}
+	for _, r := range text {
+		if (r < '0' || r > '9') && r != '.' && r != ',' {
+			return 0, errPriceFormat
+		}
+	}
+
+	// Split off the fraction after the decimal separator, if any
+	whole, fraction := text, ""
+	if last := strings.LastIndexAny(text, ".,"); last >= 0 {
+		separator := text[last : last+1]
+		mixed := strings.Contains(text, ".") && strings.Contains(text, ",")
+		if mixed || strings.Count(text, separator) == 1 {
+			whole, fraction = text[:last], text[last+1:]
+			if !mixed && len(fraction) == 3 && whole != "" && whole != "0" {
+				return 0, errAmbiguousPrice
+			}
+			if fraction == "" || strings.Contains(whole, separator) {
+				return 0, errPriceFormat
+			}
+		}
+	}
+
+	// Remove thousands separators, which must group the digits by three
+	if group := strings.Trim(whole, "0123456789"); group != "" {
+		groups := strings.Split(whole, group[:1])
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return 0, errPriceFormat
+		}
+		for _, digits := range groups[1:] {
+			if len(digits) != 3 || strings.ContainsAny(digits, ".,") {
+				return 0, errPriceFormat
+			}
+		}
+		whole = strings.Join(groups, "")
+	}
+	if fraction == "" {
+		return strconv.ParseFloat(whole, 64)
+	}
+	return strconv.ParseFloat("0"+whole+"."+fraction, 64)
+}
+
+// isField reports whether name is one of Fields
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlank reports whether every value of a record is empty
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := "\ufeffName;Fiyat;SKU;quantity\n" +
+		"Lamba;12,50;LMP-1;3\n" +
+		";;;\n" +
+		"\"Masa; ahşap\";abc;TBL-1;\n"
+	rows, fields, err := ReadCSV(strings.NewReader(input), Mapping{"price": "fiyat"}, ';')
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if want := []string{"name", "price", "quantity", "sku"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("ReadCSV returned %d rows, want 2: %+v", len(rows), rows)
+	}
+	if row := rows[0]; row.Line != 2 || row.Err != "" || row.Product.Name != "Lamba" || row.Product.Price != 12.5 || row.Product.Quantity != 3 {
+		t.Errorf("rows[0] = %+v", row)
+	}
+	if row := rows[1]; row.Line != 4 || !strings.HasPrefix(row.Err, `Invalid price "abc"`) || row.Product.Name != "Masa; ahşap" {
+		t.Errorf("rows[1] = %+v", row)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty file", "", "CSV file is empty"},
+		{"missing column", "name,sku\nLamba,LMP-1\n", "CSV has no column for price, add one or map it"},
+		{"bare quote", "name,price,sku\nLa\"mba,10,LMP-1\n", "invalid CSV on line 2"},
+		{"unterminated quote", "name,price,sku\nLamba,10,LMP-1\n\"Masa,20,TBL-1\n", "invalid CSV on line 3"},
+		{"malformed header", "name,\"price,sku\n", "cannot read CSV header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadCSV(strings.NewReader(tt.input), Mapping{}, ',')
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("ReadCSV error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+		err  error
+	}{
+		{"", 0, nil},
+		{"1250", 1250, nil},
+		{"12.5", 12.5, nil},
+		{"12,5", 12.5, nil},
+		{"12,50", 12.5, nil},
+		{"0.125", 0.125, nil},
+		{",75", 0.75, nil},
+		{"1.234,56", 1234.56, nil},
+		{"1,234.56", 1234.56, nil},
+		{"1.234.567,8", 1234567.8, nil},
+		{"1,234,567", 1234567, nil},
+		{"1.234.567", 1234567, nil},
+		{"1.250", 0, errAmbiguousPrice},
+		{"1,250", 0, errAmbiguousPrice},
+		{"12.", 0, errPriceFormat},
+		{"1.23.45", 0, errPriceFormat},
+		{"1234.567,8", 0, errPriceFormat},
+		{"1.2345,6", 0, errPriceFormat},
+		{"1,234.5,6", 0, errPriceFormat},
+		{"1.234.", 0, errPriceFormat},
+		{".5.", 0, errPriceFormat},
+		{"-5", 0, errPriceFormat},
+		{"1e3", 0, errPriceFormat},
+		{"NaN", 0, errPriceFormat},
+		{"12 TL", 0, errPriceFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parsePrice(tt.text)
+			if err != tt.err || got != tt.want {
+				t.Errorf("parsePrice(%q) = %v, %v, want %v, %v", tt.text, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Package jobs runs long operations such as imports and exports in the
+// background. Jobs are persisted in the database, picked up by a pool of
+// workers, report their progress and can be cancelled. Several processes may
+// share the job database and directory: a running job is leased by the
+// process running it, which renews the lease while it runs. Jobs whose lease
+// ran out because their process crashed or stopped are queued again if
+// their type is resumable, and marked failed otherwise.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"product-api/models"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Defaults used when the environment does not configure the workers
+const (
+	DefaultWorkers     = 2
+	DefaultMaxAttempts = 3
+)
+
+// pollInterval is how often idle workers look for jobs queued by other
+// processes; jobs queued by this process wake a worker immediately
+const pollInterval = 5 * time.Second
+
+// leaseDuration is how long a running job stays with its process without a
+// renewal. Leases are renewed, and expired ones recovered, every leaseRenewal.
+const (
+	leaseDuration = 30 * time.Second
+	leaseRenewal  = leaseDuration / 3
+)
+
+// Errors returned by Manager
+var (
+	ErrNotFound       = errors.New("job not found")
+	ErrFinished       = errors.New("job already finished")
+	ErrUnknownType    = errors.New("unknown job type")
+	ErrResultNotReady = errors.New("job result not available")
+)
+
+// Config holds the job runner settings
+type Config struct {
+	Workers     int    // Number of jobs run at the same time
+	Dir         string // Directory for uploaded inputs and results, see DirFor
+	MaxAttempts int    // Starts of a resumable job before it is marked failed
+}
+
+// DirFor returns the default job directory of a job database: its path
+// without the extension, e.g. data/jobs for data/jobs.db. Results are named
+// after job IDs, so managers using different databases must not share a
+// directory.
+func DirFor(databasePath string) string {
+	return strings.TrimSuffix(databasePath, filepath.Ext(databasePath))
+}
+
+// Handler does the work of a job. It must return promptly once ctx is
+// cancelled; Run.Progress returns the context error to make that easy.
+type Handler func(ctx context.Context, run *Run) error
+
+// jobType is a registered kind of job
+type jobType struct {
+	handler   Handler
+	resumable bool
+}
+
+// Manager queues jobs and runs them on a pool of workers
+type Manager struct {
+	db     *gorm.DB
+	config Config
+	owner  string // Random ID of this manager, stored with the jobs it runs
+	types  map[string]jobType
+	wake   chan struct{}
+
+	mu      sync.Mutex
+	running map[uint]*Run // Jobs being run by this process
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a manager storing jobs in db. Register the job types
+// and then call Start. config.Dir is required.
+func NewManager(db *gorm.DB, config Config) *Manager {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	owner := make([]byte, 8)
+	rand.Read(owner)
+	return &Manager{
+		db:      db,
+		config:  config,
+		owner:   hex.EncodeToString(owner),
+		types:   make(map[string]jobType),
+		wake:    make(chan struct{}, 1),
+		running: make(map[uint]*Run),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Register adds a job type. Resumable jobs are started again from the
+// beginning when they were interrupted, so their handler must be safe to
+// repeat; other jobs are marked failed instead.
+func (m *Manager) Register(name string, handler Handler, resumable bool) {
+	m.types[name] = jobType{handler: handler, resumable: resumable}
+}
+
+// Start recovers interrupted jobs whose lease has expired and starts the
+// workers, along with the loop that renews the leases of running jobs
+func (m *Manager) Start() error {
+	if m.config.Dir == "" {
+		return errors.New("job directory not configured")
+	}
+	if err := os.MkdirAll(m.config.Dir, 0755); err != nil {
+		return fmt.Errorf("cannot create job directory: %w", err)
+	}
+	if err := m.recover(); err != nil {
+		return fmt.Errorf("cannot recover interrupted jobs: %w", err)
+	}
+
+	for i := 0; i < m.config.Workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+	m.wg.Add(1)
+	go m.maintainLeases()
+	log.Printf("Started %d job workers", m.config.Workers)
+	return nil
+}
+
+// Stop interrupts running jobs and waits for the workers to exit.
+// Interrupted jobs are handled as after a crash: resumable ones are queued
+// again, others are marked failed.
+func (m *Manager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Enqueue queues a job of a registered type. Params are stored as JSON and
+// handed to the handler through Run.Params. A non-nil input is copied to the
+// job directory and can be read by the handler through Run.Input.
+func (m *Manager) Enqueue(name string, params interface{}, input io.Reader) (models.Job, error) {
+	if _, ok := m.types[name]; !ok {
+		return models.Job{}, fmt.Errorf("%w %q", ErrUnknownType, name)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	job := models.Job{Type: name, Status: models.JobQueued, Params: string(data)}
+	if input != nil {
+		if job.InputFile, err = m.saveInput(input); err != nil {
+			return models.Job{}, err
+		}
+	}
+	if err := m.db.Create(&job).Error; err != nil {
+		removeFile(job.InputFile)
+		return models.Job{}, err
+	}
+
+	// Wake an idle worker without blocking when all are busy
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// saveInput copies an uploaded file to the job directory
+func (m *Manager) saveInput(input io.Reader) (string, error) {
+	file, err := os.CreateTemp(m.config.Dir, "input-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, input); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// Get returns a job, with the progress of running jobs as last saved
+func (m *Manager) Get(id uint) (models.Job, error) {
+	var job models.Job
+	if err := m.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return job, ErrNotFound
+		}
+		return job, err
+	}
+	return job, nil
+}
+
+// Cancel cancels a job. Queued jobs are cancelled immediately; running jobs
+// are asked to stop and become cancelled once their handler returns.
+func (m *Manager) Cancel(id uint) (models.Job, error) {
+	now := time.Now()
+	result := m.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobQueued).
+		UpdateColumns(map[string]interface{}{"status": models.JobCancelled, "finished_at": now, "updated_at": now})
+	if result.Error != nil {
+		return models.Job{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		result = m.db.Model(&models.Job{}).
+			Where("id = ? AND status = ?", id, models.JobRunning).
+			UpdateColumns(map[string]interface{}{"cancel_requested": true, "updated_at": now})
+		if result.Error != nil {
+			return models.Job{}, result.Error
+		}
+	}
+
+	job, err := m.Get(id)
+	if err != nil {
+		return job, err
+	}
+	if result.RowsAffected == 0 {
+		return job, ErrFinished
+	}
+	if job.Status == models.JobCancelled {
+		removeFile(job.InputFile)
+	}
+
+	m.mu.Lock()
+	if run, ok := m.running[id]; ok {
+		run.cancelled = true
+		run.cancel()
+	}
+	m.mu.Unlock()
+	return job, nil
+}
+
+// Result opens the result file of a succeeded job
+func (m *Manager) Result(job models.Job) (*os.File, error) {
+	if job.Status != models.JobSucceeded || job.ResultName == "" {
+		return nil, ErrResultNotReady
+	}
+	return os.Open(m.resultPath(job.ID))
+}
+
+// resultPath is where the result of a job is stored
+func (m *Manager) resultPath(id uint) string {
+	return filepath.Join(m.config.Dir, fmt.Sprintf("%d.result", id))
+}
+
+// removeFile deletes a job file, ignoring files that do not exist
+func removeFile(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Failed to remove job file %s: %v", path, err)
+	}
+}
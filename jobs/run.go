@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"product-api/models"
+	"time"
+)
+
+// progressInterval limits how often progress is written to the database
+const progressInterval = 500 * time.Millisecond
+
+// Run is the view of a running job given to its handler
+type Run struct {
+	job       models.Job
+	manager   *Manager
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cancelled bool     // Set when the job was cancelled rather than interrupted
+	result    *os.File // Open result file, if the handler created one
+	saved     time.Time
+}
+
+// ID returns the ID of the job
+func (r *Run) ID() uint {
+	return r.job.ID
+}
+
+// Params decodes the parameters the job was queued with into v
+func (r *Run) Params(v interface{}) error {
+	return json.Unmarshal([]byte(r.job.Params), v)
+}
+
+// Input opens the file uploaded with the job
+func (r *Run) Input() (*os.File, error) {
+	return os.Open(r.job.InputFile)
+}
+
+// SetTotal sets the number of units of work, e.g. rows to process
+func (r *Run) SetTotal(total int64) {
+	r.job.Total = total
+	r.save()
+}
+
+// Progress records the number of units of work done. It returns the
+// context error once the job is cancelled or the manager stops, so
+// handlers can return it directly.
+func (r *Run) Progress(done int64) error {
+	r.job.Progress = done
+	if time.Since(r.saved) >= progressInterval {
+		r.save()
+	}
+	return r.ctx.Err()
+}
+
+// save writes the progress to the database and picks up cancellation
+// requested by another process
+func (r *Run) save() {
+	r.saved = time.Now()
+	db := r.manager.db
+	err := db.Model(&models.Job{}).Where("id = ?", r.job.ID).UpdateColumns(map[string]interface{}{
+		"progress":   r.job.Progress,
+		"total":      r.job.Total,
+		"updated_at": r.saved,
+	}).Error
+	if err != nil {
+		log.Printf("Failed to save progress of job %d: %v", r.job.ID, err)
+		return
+	}
+
+	var job models.Job
+	if err := db.Select("cancel_requested").First(&job, r.job.ID).Error; err == nil && job.CancelRequested {
+		r.manager.mu.Lock()
+		r.cancelled = true
+		r.manager.mu.Unlock()
+		r.cancel()
+	}
+}
+
+// CreateResult creates the downloadable result of the job. The name is
+// offered as the file name when it is downloaded.
+func (r *Run) CreateResult(name, contentType string) (io.Writer, error) {
+	file, err := os.Create(r.manager.resultPath(r.job.ID))
+	if err != nil {
+		return nil, err
+	}
+	r.result = file
+	r.job.ResultName = name
+	r.job.ResultContentType = contentType
+	return file, nil
+}
+
+// WriteResultJSON stores v as the JSON result of the job
+func (r *Run) WriteResultJSON(name string, v interface{}) error {
+	w, err := r.CreateResult(name, "application/json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,294 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"product-api/models"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errAlreadyClaimed means another worker claimed the job first
+var errAlreadyClaimed = errors.New("job already claimed")
+
+// work runs queued jobs one at a time until the manager stops
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for {
+		job, err := m.claim()
+		if err == nil {
+			m.run(job)
+			continue
+		}
+		if errors.Is(err, errAlreadyClaimed) {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to claim job: %v", err)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// claim marks the oldest queued job as running and returns it
+func (m *Manager) claim() (models.Job, error) {
+	if m.ctx.Err() != nil {
+		return models.Job{}, gorm.ErrRecordNotFound
+	}
+
+	var job models.Job
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status = ?", models.JobQueued).Order("id").First(&job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.Job{}).
+			Where("id = ? AND status = ?", job.ID, models.JobQueued).
+			UpdateColumns(map[string]interface{}{
+				"status":           models.JobRunning,
+				"attempts":         gorm.Expr("attempts + 1"),
+				"owner":            m.owner,
+				"lease_expires_at": leaseUntil(),
+				"started_at":       now,
+				"updated_at":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyClaimed
+		}
+		job.Status = models.JobRunning
+		job.Attempts++
+		job.StartedAt = &now
+		return nil
+	})
+	return job, err
+}
+
+// run runs a claimed job and records how it ended
+func (m *Manager) run(job models.Job) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	run := &Run{job: job, manager: m, ctx: ctx, cancel: cancel}
+
+	m.mu.Lock()
+	m.running[job.ID] = run
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, job.ID)
+		m.mu.Unlock()
+	}()
+
+	jobType, ok := m.types[job.Type]
+	if !ok {
+		m.finish(run, false, fmt.Errorf("%w %q", ErrUnknownType, job.Type))
+		return
+	}
+	m.finish(run, jobType.resumable, m.call(ctx, jobType.handler, run))
+}
+
+// call runs a handler, turning a panic into an error so one broken job
+// cannot take down the process
+func (m *Manager) call(ctx context.Context, handler Handler, run *Run) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %d panicked: %v", run.job.ID, r)
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, run)
+}
+
+// finish stores the final status of a job and releases its lease. A job
+// interrupted by Stop is queued again if resumable, like after a crash.
+func (m *Manager) finish(run *Run, resumable bool, err error) {
+	job := run.job
+	now := time.Now()
+	if run.result != nil {
+		if closeErr := run.result.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	m.mu.Lock()
+	cancelled := run.cancelled
+	m.mu.Unlock()
+
+	switch {
+	case err == nil:
+		job.Status = models.JobSucceeded
+		if job.Total > 0 {
+			job.Progress = job.Total
+		}
+	case cancelled:
+		job.Status = models.JobCancelled
+		if !errors.Is(err, context.Canceled) {
+			job.Error = err.Error()
+		}
+	case m.ctx.Err() != nil && resumable && job.Attempts < m.config.MaxAttempts:
+		job.Status = models.JobQueued
+		job.Progress, job.Total = 0, 0
+	case m.ctx.Err() != nil:
+		job.Status = models.JobFailed
+		job.Error = "Interrupted by server shutdown"
+		if !errors.Is(err, context.Canceled) {
+			job.Error += ": " + err.Error()
+		}
+	default:
+		job.Status = models.JobFailed
+		job.Error = err.Error()
+	}
+
+	updates := map[string]interface{}{
+		"status":           job.Status,
+		"progress":         job.Progress,
+		"total":            job.Total,
+		"error":            job.Error,
+		"owner":            "",
+		"lease_expires_at": nil,
+		"updated_at":       now,
+	}
+	if job.Status == models.JobSucceeded {
+		updates["result_name"] = job.ResultName
+		updates["result_content_type"] = job.ResultContentType
+	}
+	if job.Status != models.JobQueued {
+		updates["finished_at"] = now
+	}
+
+	// The manager may be stopping, so the update must not use its context.
+	// A job that lost its lease belongs to another process now, which may be
+	// using its files.
+	result := m.db.Model(&models.Job{}).Where("id = ? AND owner = ?", job.ID, m.owner).UpdateColumns(updates)
+	if result.Error != nil {
+		log.Printf("Failed to save status of job %d: %v", job.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("Job %d lost its lease to another process, its status was not saved", job.ID)
+		return
+	}
+	if job.Status != models.JobSucceeded {
+		removeFile(m.resultPath(job.ID))
+	}
+	if job.Status != models.JobQueued {
+		removeFile(job.InputFile)
+	}
+	if job.Status == models.JobFailed {
+		log.Printf("Job %d (%s) failed: %s", job.ID, job.Type, job.Error)
+	}
+}
+
+// maintainLeases renews the leases of the jobs run by this manager and
+// recovers jobs whose lease expired, until the manager stops
+func (m *Manager) maintainLeases() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(leaseRenewal)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.renewLeases()
+		if err := m.recover(); err != nil {
+			log.Printf("Failed to recover interrupted jobs: %v", err)
+		}
+	}
+}
+
+// renewLeases extends the leases of the jobs run by this manager. A job
+// that is no longer leased to it, because the lease expired before it could
+// be renewed and another process took the job over, is stopped.
+func (m *Manager) renewLeases() {
+	result := m.db.Model(&models.Job{}).
+		Where("owner = ? AND status = ?", m.owner, models.JobRunning).
+		UpdateColumn("lease_expires_at", leaseUntil())
+	if result.Error != nil {
+		log.Printf("Failed to renew job leases: %v", result.Error)
+		return
+	}
+
+	var leased []uint
+	if err := m.db.Model(&models.Job{}).Where("owner = ? AND status = ?", m.owner, models.JobRunning).Pluck("id", &leased).Error; err != nil {
+		log.Printf("Failed to renew job leases: %v", err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, run := range m.running {
+		if !slices.Contains(leased, id) {
+			log.Printf("Job %d lost its lease to another process, stopping it", id)
+			run.cancel()
+		}
+	}
+}
+
+// leaseUntil returns the expiry of a lease taken or renewed now. Lease times
+// are kept in UTC, since SQLite compares them as text.
+func leaseUntil() time.Time {
+	return time.Now().UTC().Add(leaseDuration)
+}
+
+// recover handles running jobs whose lease has expired, because the process
+// running them crashed or stopped. Cancelled ones become cancelled,
+// resumable ones are queued again and the rest are marked failed, since they
+// may have stopped halfway. Jobs from before leases were stored have none
+// and are recovered too.
+func (m *Manager) recover() error {
+	expired := "status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)"
+	var jobs []models.Job
+	if err := m.db.Where(expired, models.JobRunning, time.Now().UTC()).Find(&jobs).Error; err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		jobType, known := m.types[job.Type]
+		updates := map[string]interface{}{"owner": "", "lease_expires_at": nil, "updated_at": time.Now()}
+		switch {
+		case job.CancelRequested:
+			updates["status"] = models.JobCancelled
+		case known && jobType.resumable && job.Attempts < m.config.MaxAttempts:
+			updates["status"] = models.JobQueued
+			updates["progress"] = 0
+			updates["total"] = 0
+		case known && jobType.resumable:
+			updates["status"] = models.JobFailed
+			updates["error"] = fmt.Sprintf("Interrupted %d times, giving up", job.Attempts)
+		default:
+			updates["status"] = models.JobFailed
+			updates["error"] = "Interrupted by a server restart"
+		}
+
+		// Another process may recover the same job at the same time
+		result := m.db.Model(&models.Job{}).
+			Where("id = ? AND owner = ? AND "+expired, job.ID, job.Owner, models.JobRunning, time.Now().UTC()).
+			UpdateColumns(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if updates["status"] != models.JobQueued {
+			removeFile(job.InputFile)
+		}
+		removeFile(m.resultPath(job.ID))
+		log.Printf("Job %d (%s) was interrupted, now %s", job.ID, job.Type, updates["status"])
+	}
+	return nil
+}
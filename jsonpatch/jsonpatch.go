@@ -0,0 +1,323 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON values.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of the supported patch formats
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// ErrTestFailed is returned when a JSON Patch "test" operation does not match
+var ErrTestFailed = errors.New("test operation failed")
+
+// MergePatch applies an RFC 7396 merge patch to a JSON document
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+// mergeValue implements the MergePatch algorithm of RFC 7396 section 2
+func mergeValue(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+		} else {
+			targetObj[name] = mergeValue(targetObj[name], value)
+		}
+	}
+	return targetObj
+}
+
+// Operation is a single RFC 6902 patch operation
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies an RFC 6902 JSON Patch to a JSON document. Operations are
+// applied in order and the patch fails as a whole if any operation fails.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		// Type errors name the Go types, which mean nothing to the client
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, errors.New("invalid JSON patch: must be an array of operations with string op, path and from")
+		}
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		if target, err = applyOperation(target, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// applyOperation applies one operation and returns the new document root
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errors.New("missing value")
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if _, err := get(doc, path); err != nil {
+				return nil, err
+			}
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("cannot move a value into one of its children")
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// get returns the value at path
+func get(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %q", token)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path not found: %q", token)
+		}
+	}
+	return current, nil
+}
+
+// add inserts or replaces the value at path and returns the new document root
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		index := len(node)
+		if last != "-" {
+			if index, err = arrayIndex(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[index+1:], node[index:])
+		node[index] = value
+		return set(doc, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("cannot add to a non-container value")
+	}
+}
+
+// remove deletes the value at path and returns the new document root
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the document root")
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[last]; !ok {
+			return nil, fmt.Errorf("path not found: %q", last)
+		}
+		delete(node, last)
+		return doc, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node[:index], node[index+1:]...)
+		return set(doc, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("path not found: %q", last)
+	}
+}
+
+// set replaces the container at path with value, which is needed after
+// arrays change length
+func set(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array index token and checks it against max
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return index, nil
+}
+
+// isPrefix reports whether prefix is a leading part of path
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// deepCopy duplicates a decoded JSON value so copies do not share containers
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c[key] = deepCopy(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	default:
+		return value
+	}
+}
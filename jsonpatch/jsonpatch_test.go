@@ -0,0 +1,128 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// equalJSON reports whether two JSON texts encode the same value
+func equalJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// TestApply runs the examples of RFC 6902 appendix A and a few root cases.
+// An empty want means the patch must fail.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"A.1 adding an object member", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz": "qux", "foo": "bar"}`},
+		{"A.2 adding an array element", `{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`},
+		{"A.3 removing an object member", `{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`},
+		{"A.4 removing an array element", `{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`},
+		{"A.5 replacing a value", `{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`},
+		{"A.6 moving a value",
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`},
+		{"A.7 moving an array element", `{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`},
+		{"A.8 testing a value: success",
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`},
+		{"A.9 testing a value: error", `{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, ``},
+		{"A.10 adding a nested member object", `{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo": "bar", "child": {"grandchild": {}}}`},
+		{"A.11 ignoring unrecognized elements", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo": "bar", "baz": "qux"}`},
+		{"A.12 adding to a nonexistent target", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, ``},
+		{"A.13 invalid JSON patch document", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "op": "remove"}]`, ``},
+		{"A.14 ~ escape ordering", `{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/": 9, "~1": 10}`},
+		{"A.15 comparing strings and numbers", `{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": "10"}]`, ``},
+		{"A.16 adding an array value", `{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar", ["abc", "def"]]}`},
+		{"replacing the root", `{"foo": "bar"}`, `[{"op": "replace", "path": "", "value": {"baz": 1}}]`, `{"baz": 1}`},
+		{"adding the root", `{"foo": "bar"}`, `[{"op": "add", "path": "", "value": [1]}]`, `[1]`},
+		{"removing the root", `{"foo": "bar"}`, `[{"op": "remove", "path": ""}]`, ``},
+		{"replacing a missing member", `{"foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": 1}]`, ``},
+		{"copying a value", `{"foo": {"a": 1}}`, `[{"op": "copy", "from": "/foo", "path": "/bar"}, {"op": "add", "path": "/bar/b", "value": 2}]`, `{"foo": {"a": 1}, "bar": {"a": 1, "b": 2}}`},
+		{"moving into a child", `{"foo": {"a": 1}}`, `[{"op": "move", "from": "/foo", "path": "/foo/b"}]`, ``},
+		{"array index with leading zero", `{"foo": [1, 2]}`, `[{"op": "remove", "path": "/foo/01"}]`, ``},
+		{"unknown operation", `{"foo": "bar"}`, `[{"op": "merge", "path": "/foo"}]`, ``},
+		{"failed operation rolls back the patch", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": 1}, {"op": "remove", "path": "/qux"}]`, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Apply = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !equalJSON(t, got, []byte(tt.want)) {
+				t.Errorf("Apply = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTestFailed(t *testing.T) {
+	_, err := Apply([]byte(`{"baz": "qux"}`), []byte(`[{"op": "test", "path": "/baz", "value": "bar"}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("Apply error = %v, want ErrTestFailed", err)
+	}
+}
+
+// TestMergePatch runs the examples of RFC 7396 appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.doc+" + "+tt.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+			if !equalJSON(t, got, []byte(tt.want)) {
+				t.Errorf("MergePatch = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{"a":1}`), []byte(`{"a":`)); err == nil {
+		t.Error("MergePatch accepted a malformed patch")
+	}
+}
@@ -1,18 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 	// "net/http" // Removed standard library http
 	// "fmt" // Removed standard library fmt
 
-	"github.com/gofiber/fiber/v2"
-	"product-api/database"
-	"product-api/routes" // Import the routes package
+	"product-api/app"
+	"product-api/config"
 )
 
+// usage lists the commands
+const usage = `Usage:
+  product-api [flags]               run the server
+  product-api config print [flags]  print the effective configuration with secrets redacted
+
+Run "product-api -help" for the flags.`
+
 // func helloHandler(w http.ResponseWriter, r *http.Request) { // Removed net/http handler
 // 	fmt.Fprintf(w, "hello world")
 // }
@@ -28,41 +39,79 @@ import (
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Admin token in the form "Bearer <ADMIN_TOKEN>"
 func main() {
-	// Initialize database
-	database.InitDB()
-	defer database.CloseDB()
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) < 2 || args[1] != "print" {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		os.Exit(printConfig(args[2:]))
+	}
 
-	// Create a new Fiber app with custom error handling
-	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			// Return a generic error if something goes wrong
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			return c.Status(code).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		},
-	})
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		return
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	serve(cfg)
+}
 
-	// Setup routes
-	routes.SetupProductRoutes(app)
-	routes.SetupSwaggerRoutes(app) // Setup Swagger routes
+// serve runs the application until an interrupt or SIGTERM
+func serve(cfg config.Config) {
+	application, err := app.New(cfg.App())
+	if err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
 
-	// Graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		log.Println("Gracefully shutting down...")
-		_ = app.Shutdown()
-	}()
+	// Stop on interrupt or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Start the server
-	log.Println("Server starting on port 8080...")
-	if err := app.Listen(":8080"); err != nil {
+	if err := application.Start(ctx); err != nil {
+		application.Stop(context.Background())
 		log.Fatalf("Error starting server: %v", err)
 	}
-} 
\ No newline at end of file
+	<-ctx.Done()
+
+	// Graceful shutdown
+	log.Println("Gracefully shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer cancel()
+	if err := application.Stop(shutdownCtx); err != nil {
+		log.Printf("Error during shutdown: %v", err)
+	}
+}
+
+// printConfig prints the effective configuration for the flags in args and
+// returns the exit code, which is non-zero if the configuration is invalid
+func printConfig(args []string) int {
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		return 1
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"    // Waiting for a worker
+	JobRunning   = "running"   // Being run by a worker
+	JobSucceeded = "succeeded" // Finished, the result can be downloaded if it has one
+	JobFailed    = "failed"    // Finished with an error
+	JobCancelled = "cancelled" // Cancelled before it finished
+)
+
+// Job is a long-running operation run in the background by a worker
+type Job struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`          // Unique identifier
+	Type            string     `json:"type" gorm:"not null"`          // Kind of work, e.g. product_import
+	Status          string     `json:"status" gorm:"not null;index"`  // queued, running, succeeded, failed or cancelled
+	Progress        int64      `json:"progress"`                      // Units of work done, e.g. rows
+	Total           int64      `json:"total"`                         // Units of work in total, 0 while unknown
+	Error           string     `json:"error,omitempty"`               // Why the job failed
+	Attempts        int        `json:"attempts"`                      // Number of times a worker started the job
+	CancelRequested bool       `json:"cancel_requested,omitempty"`    // Cancellation was asked for while running
+	ResultURL       string     `json:"result_url,omitempty" gorm:"-"` // Where to download the result, once available
+	CreatedAt       time.Time  `json:"created_at"`                    // Time the job was queued
+	StartedAt       *time.Time `json:"started_at,omitempty"`          // Time the last attempt started
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`         // Time the job finished
+	UpdatedAt       time.Time  `json:"updated_at"`                    // Time of the last change
+
+	Params            string `json:"-"` // JSON encoded parameters of the job type
+	InputFile         string `json:"-"` // Uploaded file the job reads, removed when it finishes
+	ResultName        string `json:"-"` // File name offered when downloading the result
+	ResultContentType string `json:"-"` // Media type of the result
+
+	Owner          string     `json:"-" gorm:"index"` // Process running the job, see jobs.Manager
+	LeaseExpiresAt *time.Time `json:"-"`              // When other processes may take over a running job
+}
+
+// Finished reports whether the job has reached a final status
+func (j Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// PriceChange describes a bulk price change. Exactly one of Percent and
+// Amount is set.
+type PriceChange struct {
+	Percent *float64 `json:"percent,omitempty" validate:"required_without=amount,excluded_with=amount,gt=-100"` // Relative change, e.g. 10 raises prices by 10%
+	Amount  *float64 `json:"amount,omitempty" validate:"required_without=percent,excluded_with=percent"`        // Fixed amount added to every price
+}
+
+// PriceChangeResult reports the outcome of a bulk price change
+type PriceChangeResult struct {
+	Updated   int64 `json:"updated"`   // Number of products whose price changed
+	Unchanged int64 `json:"unchanged"` // Products whose rounded price stayed the same
+	Skipped   int64 `json:"skipped"`   // Products left unchanged because the new price would not be positive
+}
+
+// Apply returns the price after the change, rounded to cents
+func (c PriceChange) Apply(price float64) float64 {
+	if c.Percent != nil {
+		price *= 1 + *c.Percent/100
+	}
+	if c.Amount != nil {
+		price += *c.Amount
+	}
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,77 @@
+package models
+
+import "product-api/validation"
+
+// ProblemContentType is the media type of problem responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix is prepended to an error code to form the problem type URI
+const ProblemTypePrefix = "urn:product-api:problem:"
+
+// Error codes returned in the code member of problem responses. Codes are
+// stable; clients should branch on them rather than on the detail text.
+const (
+	ProblemInvalidParameter     = "invalid_parameter"      // A path, query or form parameter is malformed
+	ProblemMalformedBody        = "malformed_body"         // The request body cannot be parsed
+	ProblemValidationFailed     = "validation_failed"      // The request has invalid fields, listed in errors
+	ProblemUnauthorized         = "unauthorized"           // The admin token is missing or wrong
+	ProblemForbidden            = "forbidden"              // The operation is not allowed
+	ProblemNotFound             = "not_found"              // The resource or route does not exist
+	ProblemMethodNotAllowed     = "method_not_allowed"     // The route does not support the method
+	ProblemDuplicateValue       = "duplicate_value"        // A unique value is already used by another resource
+	ProblemConflict             = "conflict"               // The resource is not in a state that allows the operation
+	ProblemPatchTestFailed      = "patch_test_failed"      // A JSON Patch test operation did not match
+	ProblemBatchFailed          = "batch_failed"           // An operation of an atomic batch failed, so none were applied
+	ProblemPreconditionFailed   = "precondition_failed"    // If-Match does not match the current version
+	ProblemUnsupportedMediaType = "unsupported_media_type" // The Content-Type is not accepted
+	ProblemRequestTooLarge      = "request_too_large"      // The request body is too large
+	ProblemInternal             = "internal_error"         // An unexpected error occurred on the server
+	ProblemUnavailable          = "service_unavailable"    // The database is temporarily unavailable
+)
+
+// problemTitles is the fixed summary of each error code
+var problemTitles = map[string]string{
+	ProblemInvalidParameter:     "Invalid parameter",
+	ProblemMalformedBody:        "Malformed request body",
+	ProblemValidationFailed:     "Validation failed",
+	ProblemUnauthorized:         "Unauthorized",
+	ProblemForbidden:            "Forbidden",
+	ProblemNotFound:             "Not found",
+	ProblemMethodNotAllowed:     "Method not allowed",
+	ProblemDuplicateValue:       "Duplicate value",
+	ProblemConflict:             "Conflict",
+	ProblemPatchTestFailed:      "Patch test failed",
+	ProblemBatchFailed:          "Batch failed",
+	ProblemPreconditionFailed:   "Precondition failed",
+	ProblemUnsupportedMediaType: "Unsupported media type",
+	ProblemRequestTooLarge:      "Request too large",
+	ProblemInternal:             "Internal server error",
+	ProblemUnavailable:          "Service unavailable",
+}
+
+// Problem is the body of every error response, following RFC 7807
+type Problem struct {
+	Type          string            `json:"type"`                     // URI identifying the error code
+	Title         string            `json:"title"`                    // Short summary of the error code
+	Status        int               `json:"status"`                   // HTTP status code
+	Detail        string            `json:"detail,omitempty"`         // Explanation of this occurrence
+	Instance      string            `json:"instance,omitempty"`       // Path of the request
+	Code          string            `json:"code"`                     // Stable machine-readable error code
+	RequestID     string            `json:"request_id,omitempty"`     // ID of the request, also sent as X-Request-ID
+	Errors        validation.Errors `json:"errors,omitempty"`         // Invalid fields by JSON path, for validation_failed
+	ConflictingID uint              `json:"conflicting_id,omitempty"` // Resource holding the value, for duplicate_value
+
+	// Results are the failed operations of a rejected or rolled back batch
+	Results []ProductBatchResult `json:"results,omitempty"`
+}
+
+// NewProblem builds a problem with the type and title of the error code
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   ProblemTypePrefix + code,
+		Title:  problemTitles[code],
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"product-api/gtin"
+	"product-api/normalize"
 	"time"
 	
 	"gorm.io/gorm"
@@ -8,17 +10,62 @@ import (
 
 // Product represents the structure for a product
 type Product struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`           // Unique identifier
+	ID          uint           `json:"id" gorm:"primaryKey;index:idx_products_updated_at_id,priority:2"` // Unique identifier
 	Name        string         `json:"name" gorm:"not null"`           // Name of the product
 	Description string         `json:"description"`                     // Detailed description
 	Price       float64        `json:"price" gorm:"not null"`          // Price of the product
 	Quantity    int            `json:"quantity" gorm:"default:0"`      // Quantity of the product
 	CreatedAt   *time.Time     `json:"created_at,omitempty"`           // Timestamp of creation
-	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`           // Timestamp of last update
-	SKU         string         `json:"sku"`                            // Stock Keeping Unit
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty" gorm:"index:idx_products_updated_at_id,priority:1"` // Timestamp of last update
+	SKU         string         `json:"sku" gorm:"index"`               // Stock Keeping Unit
 	Barcode     string         `json:"barcode"`                        // Barcode of the product
+	BarcodeFormat string       `json:"barcode_format,omitempty"`       // Format of Barcode, e.g. EAN-13 or UPC-A
+	GTIN        string         `json:"gtin,omitempty" gorm:"column:gtin;index"` // Barcode as canonical GTIN-14, maintained by BeforeSave
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`                 // For soft delete support
+	Version     uint           `json:"version" gorm:"not null;default:1"` // Incremented on every update, used for the ETag
+
+	// Normalized copies of the text fields, maintained by BeforeSave
+	NameFolded        string `json:"-" gorm:"index"` // Case and accent folded name used for lookups
+	DescriptionFolded string `json:"-"`              // Case and accent folded description used for search
+	NameSortKey       []byte `json:"-" gorm:"index"` // Turkish collation key used for sorting by name
 	
 
 	// Add other relevant fields like SKU, Quantity, CreatedAt, UpdatedAt etc. if needed
-} 
\ No newline at end of file
+}
+
+// BeforeCreate starts new products at version 1
+func (p *Product) BeforeCreate(tx *gorm.DB) error {
+	if p.Version == 0 {
+		p.Version = 1
+	}
+	return nil
+}
+
+// BeforeSave refreshes the normalized text fields before the product is written
+func (p *Product) BeforeSave(tx *gorm.DB) error {
+	p.Normalize()
+	return nil
+}
+
+// Normalize recomputes the folded and collation fields from Name and
+// Description, and the GTIN from Barcode. A barcode that is not a valid
+// GTIN leaves the GTIN empty.
+func (p *Product) Normalize() {
+	p.NameFolded = normalize.Fold(p.Name)
+	p.DescriptionFolded = normalize.Fold(p.Description)
+	p.NameSortKey = normalize.SortKey(p.Name)
+	p.GTIN = p.CanonicalGTIN()
+}
+
+// CanonicalGTIN returns the barcode as a GTIN-14, or an empty string when the
+// product has no valid barcode
+func (p *Product) CanonicalGTIN() string {
+	if p.Barcode == "" {
+		return ""
+	}
+	code, err := gtin.Parse(p.Barcode, gtin.Format(p.BarcodeFormat))
+	if err != nil {
+		return ""
+	}
+	return code.GTIN
+}
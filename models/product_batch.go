@@ -0,0 +1,53 @@
+package models
+
+import "product-api/validation"
+
+// MaxBatchOperations is the largest number of operations accepted in one batch
+const MaxBatchOperations = 1000
+
+// Batch operation kinds
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// Batch modes
+const (
+	BatchModeAtomic  = "atomic"   // All operations succeed or none are applied
+	BatchModePerItem = "per_item" // Every operation succeeds or fails on its own
+)
+
+// ProductBatchOperation is one create, update or delete in a batch
+type ProductBatchOperation struct {
+	Op      string            `json:"op" validate:"required,oneof=create update delete"`                                     // create, update or delete
+	ID      uint              `json:"id,omitempty" label:"ID" validate:"required_if=op update delete,excluded_if=op create"` // Product to update or delete
+	Version uint              `json:"version,omitempty"`                                                                     // Optional expected version, like If-Match
+	Product *ProductUpdateDTO `json:"product,omitempty" validate:"required_if=op create update,excluded_if=op delete"`       // Product data for create and update
+}
+
+// ProductBatchRequest is the body of the batch endpoint
+type ProductBatchRequest struct {
+	Mode       string                  `json:"mode" validate:"oneof=atomic per_item"`   // atomic (default) or per_item
+	Operations []ProductBatchOperation `json:"operations" validate:"required,max=1000"` // Operations in the order they are applied, at most MaxBatchOperations
+}
+
+// ProductBatchResult is the outcome of one batch operation
+type ProductBatchResult struct {
+	Index         int               `json:"index"`                    // Position of the operation in the request
+	Op            string            `json:"op"`                       // Operation kind
+	Status        int               `json:"status"`                   // HTTP status the operation would have on its own
+	ID            uint              `json:"id,omitempty"`             // Product ID
+	Product       *Product          `json:"product,omitempty"`        // Created or updated product
+	Error         string            `json:"error,omitempty"`          // Why the operation failed
+	Errors        validation.Errors `json:"errors,omitempty"`         // Invalid fields of an invalid operation
+	ConflictingID uint              `json:"conflicting_id,omitempty"` // Product that already uses a unique value
+}
+
+// ProductBatchResponse is returned by the batch endpoint
+type ProductBatchResponse struct {
+	Mode      string               `json:"mode"`      // Mode the batch ran in
+	Succeeded int                  `json:"succeeded"` // Number of applied operations
+	Failed    int                  `json:"failed"`    // Number of failed operations
+	Results   []ProductBatchResult `json:"results"`   // Per operation results
+}
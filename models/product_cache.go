@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ProductVersion holds the fields that identify the current version of a
+// product, so it can be revalidated without loading the whole row
+type ProductVersion struct {
+	ID        uint
+	Version   uint
+	UpdatedAt *time.Time
+}
+
+// ProductTableVersion summarizes the products table. It changes whenever a
+// product is created, updated or deleted, so listings can be revalidated
+// without running the listing query.
+type ProductTableVersion struct {
+	Version int64 // Counter incremented by every write to the products table
+}
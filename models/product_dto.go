@@ -1,25 +1,32 @@
 package models
 
+import (
+	"product-api/gtin"
+	"slices"
+)
+
 // ProductCreateDTO is used for creating a new product
 // It includes only the fields that should be provided by the client
 type ProductCreateDTO struct {
-	Name        string  `json:"name"`         // Name of the product
-	Description string  `json:"description"`  // Detailed description
-	Price       float64 `json:"price"`        // Price of the product
-	Quantity    int     `json:"quantity"`     // Quantity of the product
-	SKU         string  `json:"sku"`          // Stock Keeping Unit
-	Barcode     string  `json:"barcode"`      // Barcode of the product
+	Name          string  `json:"name" validate:"required,max=255"`                                                  // Name of the product
+	Description   string  `json:"description" validate:"max=5000"`                                                   // Detailed description
+	Price         float64 `json:"price" validate:"gt=0"`                                                             // Price of the product
+	Quantity      int     `json:"quantity" validate:"gte=0"`                                                         // Quantity of the product
+	SKU           string  `json:"sku" label:"SKU" validate:"max=64,pattern=^\\S+$"`                                  // Stock Keeping Unit, without spaces
+	Barcode       string  `json:"barcode" validate:"barcode=barcode_format"`                                         // Barcode of the product
+	BarcodeFormat string  `json:"barcode_format,omitempty" validate:"oneof=EAN-8 UPC-E UPC-A EAN-13 GTIN-14 ITF-14"` // Optional, detected from the barcode when empty
 }
 
 // ToProduct converts a ProductCreateDTO to a Product entity
 func (dto *ProductCreateDTO) ToProduct() Product {
 	return Product{
-		Name:        dto.Name,
-		Description: dto.Description,
-		Price:       dto.Price,
-		Quantity:    dto.Quantity,
-		SKU:         dto.SKU,
-		Barcode:     dto.Barcode,
+		Name:          dto.Name,
+		Description:   dto.Description,
+		Price:         dto.Price,
+		Quantity:      dto.Quantity,
+		SKU:           dto.SKU,
+		Barcode:       dto.Barcode,
+		BarcodeFormat: dto.BarcodeFormat,
 		// ID, CreatedAt and UpdatedAt will be set by the repository
 	}
 }
@@ -27,12 +34,13 @@ func (dto *ProductCreateDTO) ToProduct() Product {
 // ProductUpdateDTO is used for updating an existing product
 // It's similar to ProductCreateDTO but will be applied to an existing Product
 type ProductUpdateDTO struct {
-	Name        string  `json:"name"`         // Name of the product
-	Description string  `json:"description"`  // Detailed description
-	Price       float64 `json:"price"`        // Price of the product
-	Quantity    int     `json:"quantity"`     // Quantity of the product
-	SKU         string  `json:"sku"`          // Stock Keeping Unit
-	Barcode     string  `json:"barcode"`      // Barcode of the product
+	Name          string  `json:"name" validate:"required,max=255"`                                                  // Name of the product
+	Description   string  `json:"description" validate:"max=5000"`                                                   // Detailed description
+	Price         float64 `json:"price" validate:"gt=0"`                                                             // Price of the product
+	Quantity      int     `json:"quantity" validate:"gte=0"`                                                         // Quantity of the product
+	SKU           string  `json:"sku" label:"SKU" validate:"max=64,pattern=^\\S+$"`                                  // Stock Keeping Unit, without spaces
+	Barcode       string  `json:"barcode" validate:"barcode=barcode_format"`                                         // Barcode of the product
+	BarcodeFormat string  `json:"barcode_format,omitempty" validate:"oneof=EAN-8 UPC-E UPC-A EAN-13 GTIN-14 ITF-14"` // Optional, detected from the barcode when empty
 }
 
 // ApplyToProduct updates an existing Product with the DTO values
@@ -43,5 +51,95 @@ func (dto *ProductUpdateDTO) ApplyToProduct(product *Product) {
 	product.Quantity = dto.Quantity
 	product.SKU = dto.SKU
 	product.Barcode = dto.Barcode
+	product.BarcodeFormat = dto.BarcodeFormat
 	// ID remains unchanged, UpdatedAt will be set by the repository
-} 
\ No newline at end of file
+}
+
+// NewProductUpdateDTO returns the updatable fields of an existing product
+func NewProductUpdateDTO(product Product) ProductUpdateDTO {
+	return ProductUpdateDTO{
+		Name:          product.Name,
+		Description:   product.Description,
+		Price:         product.Price,
+		Quantity:      product.Quantity,
+		SKU:           product.SKU,
+		Barcode:       product.Barcode,
+		BarcodeFormat: product.BarcodeFormat,
+	}
+}
+
+// ChangedColumns returns the database columns whose values in the DTO differ from the product
+func (dto *ProductUpdateDTO) ChangedColumns(product Product) []string {
+	var columns []string
+	if dto.Name != product.Name {
+		columns = append(columns, "name")
+	}
+	if dto.Description != product.Description {
+		columns = append(columns, "description")
+	}
+	if dto.Price != product.Price {
+		columns = append(columns, "price")
+	}
+	if dto.Quantity != product.Quantity {
+		columns = append(columns, "quantity")
+	}
+	if dto.SKU != product.SKU {
+		columns = append(columns, "sku")
+	}
+	if dto.Barcode != product.Barcode || dto.BarcodeFormat != product.BarcodeFormat {
+		columns = append(columns, "barcode")
+	}
+	return columns
+}
+
+// KeepColumns resets the updatable fields of the product outside the given
+// columns, named like ChangedColumns, to their values in stored
+func (p *Product) KeepColumns(stored Product, columns []string) {
+	if !slices.Contains(columns, "name") {
+		p.Name = stored.Name
+	}
+	if !slices.Contains(columns, "description") {
+		p.Description = stored.Description
+	}
+	if !slices.Contains(columns, "price") {
+		p.Price = stored.Price
+	}
+	if !slices.Contains(columns, "quantity") {
+		p.Quantity = stored.Quantity
+	}
+	if !slices.Contains(columns, "sku") {
+		p.SKU = stored.SKU
+	}
+	if !slices.Contains(columns, "barcode") {
+		p.Barcode = stored.Barcode
+		p.BarcodeFormat = stored.BarcodeFormat
+	}
+}
+
+// NormalizeBarcode validates the barcode and its check digit, strips
+// separators and fills in the detected format
+func (dto *ProductCreateDTO) NormalizeBarcode() error {
+	return normalizeBarcode(&dto.Barcode, &dto.BarcodeFormat)
+}
+
+// NormalizeBarcode validates the barcode and its check digit, strips
+// separators and fills in the detected format
+func (dto *ProductUpdateDTO) NormalizeBarcode() error {
+	return normalizeBarcode(&dto.Barcode, &dto.BarcodeFormat)
+}
+
+// normalizeBarcode validates a barcode in place. An empty barcode is allowed
+// and clears the format.
+func normalizeBarcode(barcode, format *string) error {
+	if *barcode == "" {
+		*format = ""
+		return nil
+	}
+	code, err := gtin.Parse(*barcode, gtin.Format(*format))
+	if err != nil {
+		return err
+	}
+	*barcode = code.Digits
+	*format = string(code.Format)
+	return nil
+}
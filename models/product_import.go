@@ -0,0 +1,31 @@
+package models
+
+// Actions reported for imported rows
+const (
+	ImportCreated  = "created"  // A new product was created, or a deleted one revived
+	ImportUpdated  = "updated"  // An existing product was replaced
+	ImportRejected = "rejected" // The row was not imported
+)
+
+// ProductImportRow reports what happened to one row of an import
+type ProductImportRow struct {
+	Line          int    `json:"line"`                     // Line of the row in the file, the header is line 1
+	SKU           string `json:"sku,omitempty"`            // SKU of the row
+	Action        string `json:"action"`                   // created, updated or rejected
+	ID            uint   `json:"id,omitempty"`             // ID of the product, not set for creates in a dry run
+	Reason        string `json:"reason,omitempty"`         // Why the row was rejected
+	ConflictingID uint   `json:"conflicting_id,omitempty"` // Product that already uses the row's barcode
+}
+
+// ProductImportReport is returned by the import endpoint
+type ProductImportReport struct {
+	DryRun   bool               `json:"dry_run"`  // Whether the import was only simulated
+	Total    int                `json:"total"`    // Number of rows read
+	Created  int                `json:"created"`  // Number of created or revived products
+	Updated  int                `json:"updated"`  // Number of replaced products
+	Rejected int                `json:"rejected"` // Number of rejected rows
+	Rows     []ProductImportRow `json:"rows"`     // Per row results in file order
+
+	// UntouchedFields have no column in the file; updated products keep their stored values
+	UntouchedFields []string `json:"untouched_fields,omitempty"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// Default and maximum page sizes for product listings
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// SortField describes a single column used to order a product listing
+type SortField struct {
+	Column string // Database column name
+	Desc   bool   // True for descending order
+}
+
+// ProductQuery holds the pagination, filtering and sorting options
+// used when listing products
+type ProductQuery struct {
+	Limit       int         // Maximum number of items to return
+	Offset      int         // Number of items to skip
+	Name        string      // Partial, case and accent insensitive match on product name
+	SKU         string      // Exact match on SKU
+	Barcode     string      // Exact match on barcode
+	MinPrice    *float64    // Lower bound for price (inclusive)
+	MaxPrice    *float64    // Upper bound for price (inclusive)
+	MinQuantity *int        // Lower bound for quantity (inclusive)
+	MaxQuantity *int        // Upper bound for quantity (inclusive)
+	Sort        []SortField // Ordering of the result set
+}
+
+// PageMeta describes the position of a page within the full result set
+type PageMeta struct {
+	Total      int64 `json:"total"`       // Total number of matching products
+	Page       int   `json:"page"`        // Current page number (1-based)
+	PageSize   int   `json:"page_size"`   // Number of items per page
+	TotalPages int   `json:"total_pages"` // Total number of pages
+	Offset     int   `json:"offset"`      // Number of items skipped
+}
+
+// ProductListResponse is returned by the product listing endpoint
+type ProductListResponse struct {
+	Items  []Product `json:"items"`            // Products on the current page
+	Meta   PageMeta  `json:"meta"`             // Paging metadata
+	Facets Facets    `json:"facets,omitempty"` // Requested facet counts over all matching products
+}
+
+// NewPageMeta builds paging metadata from a query and the total item count
+func NewPageMeta(query ProductQuery, total int64) PageMeta {
+	meta := PageMeta{
+		Total:    total,
+		PageSize: query.Limit,
+		Offset:   query.Offset,
+		Page:     1,
+	}
+	if query.Limit > 0 {
+		meta.Page = query.Offset/query.Limit + 1
+		meta.TotalPages = int((total + int64(query.Limit) - 1) / int64(query.Limit))
+	}
+	return meta
+}
+
+// ProductSortColumns maps the sort keys accepted by the API to database columns
+var ProductSortColumns = map[string]string{
+	"id":         "products.id",
+	"name":       "products.name_sort_key",
+	"price":      "products.price",
+	"quantity":   "products.quantity",
+	"sku":        "products.sku",
+	"barcode":    "products.barcode",
+	"created_at": "products.created_at",
+	"updated_at": "products.updated_at",
+}
+
+// KeysetPosition identifies the last product returned by a keyset scan.
+// Products are ordered by (updated_at, id), so the next page starts
+// strictly after this position.
+type KeysetPosition struct {
+	UpdatedAt time.Time // UpdatedAt of the last product seen
+	ID        uint      // ID of the last product seen
+}
+
+// ProductCursorResponse is returned by the product listing endpoint in cursor mode
+type ProductCursorResponse struct {
+	Items      []Product `json:"items"`                 // Products on the current page
+	NextCursor string    `json:"next_cursor,omitempty"` // Opaque cursor for the next page
+	HasMore    bool      `json:"has_more"`              // Whether more products follow
+}
+
+// Facet names accepted by the listing and search endpoints
+const (
+	FacetPrice = "price" // Products per price bucket
+	FacetStock = "stock" // In-stock and out-of-stock products
+)
+
+// ProductFacetNames lists the facets that can be requested
+var ProductFacetNames = map[string]bool{
+	FacetPrice: true,
+	FacetStock: true,
+}
+
+// DefaultPriceBuckets are the price bucket boundaries used when none are given
+var DefaultPriceBuckets = []float64{50, 100, 250, 500, 1000}
+
+// MaxPriceBuckets limits the number of price bucket boundaries per request
+const MaxPriceBuckets = 20
+
+// FacetRequest lists the facets to compute for a product listing
+type FacetRequest struct {
+	Names        []string  // Requested facet names
+	PriceBuckets []float64 // Ascending price bucket boundaries
+}
+
+// FacetBucket is a single value of a facet with the number of matching products
+type FacetBucket struct {
+	Key   string   `json:"key"`            // Bucket label, e.g. "50-100" or "in_stock"
+	From  *float64 `json:"from,omitempty"` // Lower bound (inclusive) for range buckets
+	To    *float64 `json:"to,omitempty"`   // Upper bound (exclusive) for range buckets
+	Count int64    `json:"count"`          // Number of matching products
+}
+
+// Facets maps facet names to their buckets
+type Facets map[string][]FacetBucket
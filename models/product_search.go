@@ -0,0 +1,30 @@
+package models
+
+// ProductSearchHit is a product matched by full-text search
+type ProductSearchHit struct {
+	Product
+	Rank               float64 `json:"rank"`                // BM25 relevance, lower is better
+	Score              float64 `json:"score"`               // Relevance after ranking boosts, higher is better
+	NameHighlight      string  `json:"name_highlight"`      // HTML-escaped name with matched terms wrapped in <mark>
+	DescriptionSnippet string  `json:"description_snippet"` // HTML-escaped excerpt of the description around the matches, marked like name_highlight
+}
+
+// ProductSearchResponse is returned by the product search endpoint
+type ProductSearchResponse struct {
+	Items  []ProductSearchHit `json:"items"`            // Matching products ordered by relevance
+	Meta   PageMeta           `json:"meta"`             // Paging metadata
+	Facets Facets             `json:"facets,omitempty"` // Requested facet counts over all matching products
+}
+
+// ProductSuggestion is an autocomplete suggestion for the search box
+type ProductSuggestion struct {
+	ID    uint    `json:"id"`    // Product ID
+	Name  string  `json:"name"`  // Product name
+	SKU   string  `json:"sku"`   // Stock Keeping Unit
+	Score float64 `json:"score"` // Match quality, higher is better
+}
+
+// ProductSuggestResponse is returned by the product suggest endpoint
+type ProductSuggestResponse struct {
+	Items []ProductSuggestion `json:"items"` // Suggestions ordered by score
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TrashedProduct is a soft-deleted product together with its deletion time
+type TrashedProduct struct {
+	Product
+	DeletedAt time.Time `json:"deleted_at"` // When the product was moved to the trash
+}
+
+// NewTrashedProduct wraps a soft-deleted product for the trash listing
+func NewTrashedProduct(product Product) TrashedProduct {
+	return TrashedProduct{Product: product, DeletedAt: product.DeletedAt.Time}
+}
+
+// ProductTrashResponse is returned by the trash listing endpoint
+type ProductTrashResponse struct {
+	Items []TrashedProduct `json:"items"` // Trashed products, most recently deleted first
+	Meta  PageMeta         `json:"meta"`  // Paging metadata
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"product-api/normalize"
+	"time"
+)
+
+// Synonym maps a search term to other terms that should match as well,
+// e.g. "tv" to "televizyon". Terms are stored folded, see normalize.Fold.
+type Synonym struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`             // Unique identifier
+	Term      string     `json:"term" gorm:"not null;uniqueIndex"` // Word typed by the user
+	Synonyms  []string   `json:"synonyms" gorm:"serializer:json"`  // Words or phrases searched in addition
+	CreatedAt *time.Time `json:"created_at,omitempty"`             // Timestamp of creation
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`             // Timestamp of last update
+}
+
+// SynonymDTO is used for creating or replacing a synonym entry
+type SynonymDTO struct {
+	Term     string   `json:"term" validate:"required,single_word"`       // Single word typed by the user
+	Synonyms []string `json:"synonyms" validate:"required,dive,required"` // Words or phrases searched in addition
+}
+
+// ApplyToSynonym copies the folded DTO values onto a synonym entry
+func (dto *SynonymDTO) ApplyToSynonym(synonym *Synonym) {
+	synonym.Term = normalize.Fold(dto.Term)
+	synonym.Synonyms = make([]string, 0, len(dto.Synonyms))
+	for _, s := range dto.Synonyms {
+		synonym.Synonyms = append(synonym.Synonyms, normalize.Fold(s))
+	}
+}
+
+// SearchRanking holds the boosts applied on top of text relevance when
+// ordering search results. A boost of 0 disables the rule; a boost of 1
+// doubles the score of matching products.
+type SearchRanking struct {
+	ID                  uint       `json:"-" gorm:"primaryKey"`
+	InStockBoost        float64    `json:"in_stock_boost" validate:"gte=0"`        // Boost for products with Quantity > 0
+	RecencyBoost        float64    `json:"recency_boost" validate:"gte=0"`         // Boost for recently created products
+	RecencyHalfLifeDays float64    `json:"recency_half_life_days" validate:"gt=0"` // Age in days at which the recency boost is halved
+	ExactSKUBoost       float64    `json:"exact_sku_boost" validate:"gte=0"`       // Boost when the query equals the SKU
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`                   // Timestamp of last update
+}
+
+// DefaultSearchRanking returns the ranking used until an admin changes it
+func DefaultSearchRanking() SearchRanking {
+	return SearchRanking{
+		InStockBoost:        1,
+		RecencyBoost:        0.5,
+		RecencyHalfLifeDays: 30,
+		ExactSKUBoost:       10,
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"product-api/gtin"
+	"product-api/validation"
+	"reflect"
+	"strings"
+)
+
+// init registers the validation rules specific to this API. Every custom
+// rule used in a validate tag is registered here.
+func init() {
+	// barcode=format_field checks a barcode and its check digit, in the
+	// format named by the sibling field or detected when that is empty
+	validation.Register("barcode", validation.Rule{
+		Check: func(f validation.Field) string {
+			format := ""
+			if f.Param != "" {
+				if value := f.Sibling(f.Param).Value; value.IsValid() {
+					format = value.String()
+				}
+			}
+			if _, err := gtin.Parse(f.Value.String(), gtin.Format(format)); err != nil {
+				return "Invalid barcode: " + err.Error()
+			}
+			return ""
+		},
+		Schema: func(schema validation.Schema, param string, t reflect.Type) {
+			schema["pattern"] = `^[0-9 -]+$`
+		},
+	})
+
+	// single_word requires a string to be one word, ignoring surrounding spaces
+	validation.Register("single_word", validation.Rule{
+		Check: func(f validation.Field) string {
+			if len(strings.Fields(f.Value.String())) != 1 {
+				return f.Label + " must be a single word"
+			}
+			return ""
+		},
+		Schema: func(schema validation.Schema, param string, t reflect.Type) {
+			schema["pattern"] = `^\s*\S+\s*$`
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Package normalize provides Turkish-aware text folding and collation used
+// for product lookups, sorting and search.
+package normalize
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold converts text into a form suitable for case and accent insensitive
+// matching. All variants of the letter I (I, İ, ı, i) become "i", diacritics
+// are removed (ş → s, ğ → g, ç → c, ö → o, ü → u) and everything is lowercased,
+// so "IŞIK", "ışık" and "isik" all fold to "isik".
+func Fold(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	// NFD splits letters such as "ş" and "İ" into a base letter and combining marks
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		switch r {
+		case 'I', 'ı':
+			r = 'i'
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	// collator orders strings by the Turkish alphabet (c < ç < d, ı < i, ...)
+	collator   = collate.New(language.Turkish, collate.IgnoreCase)
+	collatorMu sync.Mutex // collate.Collator is not safe for concurrent use
+	keyBuffer  collate.Buffer
+)
+
+// SortKey returns a binary key that sorts text in Turkish alphabetical order
+// when compared byte by byte, as SQLite does for BLOB columns.
+func SortKey(text string) []byte {
+	collatorMu.Lock()
+	defer collatorMu.Unlock()
+	key := append([]byte(nil), collator.KeyFromString(&keyBuffer, text)...)
+	keyBuffer.Reset()
+	return key
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the repositories. Database errors are translated into
+// them so that callers can tell, for example, a missing product from an
+// outage. They may wrap the underlying error, so check them with errors.Is.
+var (
+	ErrNotFound        = errors.New("not found")                   // The record does not exist
+	ErrConflict        = errors.New("unique value already in use") // A unique value is taken, see ConflictError
+	ErrVersionMismatch = errors.New("version mismatch")            // The record was changed since the expected version
+	ErrUnavailable     = errors.New("database unavailable")        // The database is locked, full, corrupt or closed
+)
+
+// SQLite result codes, see https://www.sqlite.org/rescode.html. Extended
+// codes carry the primary code in their lowest byte.
+const (
+	sqliteBusy                 = 5
+	sqliteLocked               = 6
+	sqliteNoMem                = 7
+	sqliteReadOnly             = 8
+	sqliteIOErr                = 10
+	sqliteCorrupt              = 11
+	sqliteFull                 = 13
+	sqliteCantOpen             = 14
+	sqliteNotADB               = 26
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+// sqliteError is implemented by the errors of the SQLite driver
+type sqliteError interface {
+	error
+	Code() int
+}
+
+// translate converts an error from GORM or SQLite into the matching
+// repository error, wrapping the original so that it is still visible in
+// logs. Errors that are already translated, that come from callbacks such as
+// progress functions, or that have no matching repository error are
+// returned unchanged.
+func translate(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	var sqliteErr sqliteError
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		switch code {
+		case sqliteConstraintUnique, sqliteConstraintPrimaryKey:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		}
+		switch code & 0xff {
+		case sqliteBusy, sqliteLocked, sqliteNoMem, sqliteReadOnly, sqliteIOErr,
+			sqliteCorrupt, sqliteFull, sqliteCantOpen, sqliteNotADB:
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		return err
+	}
+
+	// database/sql reports a closed pool with an unexported error
+	if errors.Is(err, sql.ErrConnDone) || errors.Is(err, driver.ErrBadConn) ||
+		strings.Contains(err.Error(), "sql: database is closed") {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+// isTranslated reports whether err already is a repository error
+func isTranslated(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) ||
+		errors.Is(err, ErrVersionMismatch) || errors.Is(err, ErrUnavailable)
+}
+
+// isUniqueViolation reports whether err was caused by a unique index
+func isUniqueViolation(err error) bool {
+	return errors.Is(translate(err), ErrConflict)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"html"
+	"product-api/normalize"
+	"strings"
+	"unicode"
+)
+
+// Markers placed around matched words in highlights and snippets
+const (
+	highlightStart  = "<mark>"
+	highlightEnd    = "</mark>"
+	snippetEllipsis = "…"
+)
+
+// textSegment is a run of either word or separator characters
+type textSegment struct {
+	text   string
+	isWord bool
+}
+
+// splitSegments breaks text into alternating word and separator segments
+func splitSegments(text string) []textSegment {
+	var segments []textSegment
+	start := 0
+	inWord := false
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsNumber(r)
+		if i > 0 && word != inWord {
+			segments = append(segments, textSegment{text: text[start:i], isWord: inWord})
+			start = i
+		}
+		inWord = word
+	}
+	if start < len(text) {
+		segments = append(segments, textSegment{text: text[start:], isWord: inWord})
+	}
+	return segments
+}
+
+// searchTerms returns the folded words of a search query
+func searchTerms(text string) []string {
+	var terms []string
+	for _, segment := range splitSegments(normalize.Fold(text)) {
+		if segment.isWord {
+			terms = append(terms, segment.text)
+		}
+	}
+	return terms
+}
+
+// matchesTerm reports whether a word starts with any of the folded terms
+func matchesTerm(word string, terms []string) bool {
+	folded := normalize.Fold(word)
+	for _, term := range terms {
+		if strings.HasPrefix(folded, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight returns text as HTML with every word that matches a search term
+// wrapped in <mark> tags. Matching is done on folded text, so the original
+// spelling is preserved.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	writeHighlighted(&b, splitSegments(text), terms)
+	return b.String()
+}
+
+// writeHighlighted writes segments to b as HTML, escaping their text so that
+// only the <mark> tags around matching words are markup
+func writeHighlighted(b *strings.Builder, segments []textSegment, terms []string) {
+	for _, segment := range segments {
+		if segment.isWord && matchesTerm(segment.text, terms) {
+			b.WriteString(highlightStart + html.EscapeString(segment.text) + highlightEnd)
+		} else {
+			b.WriteString(html.EscapeString(segment.text))
+		}
+	}
+}
+
+// snippet returns about maxWords words of text centred on the first match as
+// highlighted HTML, with an ellipsis where text was cut
+func snippet(text string, terms []string, maxWords int) string {
+	segments := splitSegments(text)
+
+	// Locate the first matching word and count words
+	var wordIndexes []int
+	first := -1
+	for i, segment := range segments {
+		if segment.isWord {
+			if first < 0 && matchesTerm(segment.text, terms) {
+				first = len(wordIndexes)
+			}
+			wordIndexes = append(wordIndexes, i)
+		}
+	}
+	if len(wordIndexes) <= maxWords {
+		return highlight(text, terms)
+	}
+
+	// Choose a window of words around the first match
+	startWord := 0
+	if first > maxWords/2 {
+		startWord = first - maxWords/2
+	}
+	if startWord+maxWords > len(wordIndexes) {
+		startWord = len(wordIndexes) - maxWords
+	}
+	from := wordIndexes[startWord]
+	to := wordIndexes[startWord+maxWords-1]
+
+	var b strings.Builder
+	if startWord > 0 {
+		b.WriteString(snippetEllipsis)
+	}
+	writeHighlighted(&b, segments[from:to+1], terms)
+	if startWord+maxWords < len(wordIndexes) {
+		b.WriteString(snippetEllipsis)
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package repository
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name, text, query, want string
+	}{
+		{"prefix match", "Cam Bardak", "bar", "Cam <mark>Bardak</mark>"},
+		{"folded match keeps spelling", "Işıklı Ayna", "isikli", "<mark>Işıklı</mark> Ayna"},
+		{"no match", "Cam Bardak", "tabak", "Cam Bardak"},
+		{"markup is escaped", `<img src=x onerror=alert(1)> Lamba`, "lamba", `&lt;img src=x onerror=alert(1)&gt; <mark>Lamba</mark>`},
+		{"matched word next to markup", `<b>Lamba</b> & "Abajur"`, "lamba abajur", `&lt;b&gt;<mark>Lamba</mark>&lt;/b&gt; &amp; &#34;<mark>Abajur</mark>&#34;`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlight(tt.text, searchTerms(tt.query)); got != tt.want {
+				t.Errorf("highlight(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name, text, query string
+		maxWords          int
+		want              string
+	}{
+		{"short text", "Sağlam <cam> bardak", "bardak", 5, "Sağlam &lt;cam&gt; <mark>bardak</mark>"},
+		{"cut on both sides", "bir iki üç dört <beş> altı yedi sekiz", "dört", 3, "…üç <mark>dört</mark> &lt;beş…"},
+		{"match at the start", "bardak iki üç dört", "bardak", 2, "<mark>bardak</mark> iki…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(tt.text, searchTerms(tt.query), tt.maxWords); got != tt.want {
+				t.Errorf("snippet(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
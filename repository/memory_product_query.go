@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math"
+	"product-api/gtin"
+	"product-api/models"
+	"product-api/normalize"
+	"sort"
+	"strings"
+	"time"
+)
+
+// isLive reports whether a product is not in the trash
+func isLive(product models.Product) bool {
+	return !product.DeletedAt.Valid
+}
+
+// liveMatching returns a filter for live products matching the query's
+// filters
+func liveMatching(query models.ProductQuery) func(models.Product) bool {
+	return func(product models.Product) bool {
+		return isLive(product) && matchesFilters(product, query)
+	}
+}
+
+// matchesFilters is applyProductFilters for a single product
+func matchesFilters(product models.Product, query models.ProductQuery) bool {
+	if query.Name != "" && !likeContains(product.NameFolded, normalize.Fold(query.Name)) {
+		return false
+	}
+	if query.SKU != "" && product.SKU != query.SKU {
+		return false
+	}
+	if query.Barcode != "" {
+		// Any valid form of a barcode matches its canonical GTIN-14
+		if code, err := gtin.ToGTIN14(query.Barcode); err == nil {
+			if product.GTIN != code {
+				return false
+			}
+		} else if product.Barcode != query.Barcode {
+			return false
+		}
+	}
+	if query.MinPrice != nil && product.Price < *query.MinPrice {
+		return false
+	}
+	if query.MaxPrice != nil && product.Price > *query.MaxPrice {
+		return false
+	}
+	if query.MinQuantity != nil && product.Quantity < *query.MinQuantity {
+		return false
+	}
+	if query.MaxQuantity != nil && product.Quantity > *query.MaxQuantity {
+		return false
+	}
+	return true
+}
+
+// likeContains reports whether text contains pattern, ignoring case, like the
+// escaped LIKE of applyProductFilters. % and _ match only themselves.
+func likeContains(text, pattern string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(pattern))
+}
+
+// afterPosition reports whether a product comes strictly after a keyset
+// position in (updated_at, id) order. Like SQL, a product without an update
+// time never does.
+func afterPosition(product models.Product, position models.KeysetPosition) bool {
+	if product.UpdatedAt == nil {
+		return false
+	}
+	if !product.UpdatedAt.Equal(position.UpdatedAt) {
+		return product.UpdatedAt.After(position.UpdatedAt)
+	}
+	return product.ID > position.ID
+}
+
+// sortProducts is applyProductSort for products in memory: it orders them
+// by the given columns and then by ID
+func sortProducts(products []models.Product, order []models.SortField) {
+	sort.SliceStable(products, func(i, j int) bool {
+		return compareProducts(products[i], products[j], order) < 0
+	})
+}
+
+// compareProducts compares two products by the given columns and then by ID
+func compareProducts(a, b models.Product, order []models.SortField) int {
+	for _, field := range order {
+		c := compareColumn(a, b, field.Column)
+		if field.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareColumn compares two products by a column of
+// models.ProductSortColumns or by deleted_at
+func compareColumn(a, b models.Product, column string) int {
+	switch column {
+	case "products.id":
+		return cmp.Compare(a.ID, b.ID)
+	case "products.name_sort_key":
+		return bytes.Compare(a.NameSortKey, b.NameSortKey)
+	case "products.price":
+		return cmp.Compare(a.Price, b.Price)
+	case "products.quantity":
+		return cmp.Compare(a.Quantity, b.Quantity)
+	case "products.sku":
+		return strings.Compare(a.SKU, b.SKU)
+	case "products.barcode":
+		return strings.Compare(a.Barcode, b.Barcode)
+	case "products.created_at":
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	case "products.updated_at":
+		return compareTimes(a.UpdatedAt, b.UpdatedAt)
+	case "products.deleted_at":
+		return compareTimes(deletedAt(a), deletedAt(b))
+	default:
+		panic(fmt.Sprintf("repository: cannot sort by %q", column))
+	}
+}
+
+// compareTimes compares two optional times; like NULL in SQL, a missing time
+// comes first
+func compareTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(*b)
+}
+
+// deletedAt returns when a product was trashed, or nil if it is live
+func deletedAt(product models.Product) *time.Time {
+	if !product.DeletedAt.Valid {
+		return nil
+	}
+	return &product.DeletedAt.Time
+}
+
+// page applies SQL LIMIT and OFFSET to a slice; a negative limit means no limit
+func page[T any](items []T, limit, offset int) []T {
+	if offset > 0 {
+		items = items[min(offset, len(items)):]
+	}
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// memoryFacets is computeFacets for products in memory
+func memoryFacets(products []models.Product, request models.FacetRequest) (models.Facets, error) {
+	facets := make(models.Facets, len(request.Names))
+	for _, name := range request.Names {
+		counts := make(map[int]int64)
+		switch name {
+		case models.FacetPrice:
+			for _, product := range products {
+				counts[sort.Search(len(request.PriceBuckets), func(i int) bool {
+					return product.Price < request.PriceBuckets[i]
+				})]++
+			}
+			facets[name] = priceBuckets(request.PriceBuckets, counts)
+		case models.FacetStock:
+			for _, product := range products {
+				if product.Quantity > 0 {
+					counts[1]++
+				} else {
+					counts[0]++
+				}
+			}
+			facets[name] = stockBuckets(counts)
+		default:
+			return nil, fmt.Errorf("unknown facet %q", name)
+		}
+	}
+	return facets, nil
+}
+
+// Search runs a full-text query against product names, descriptions and
+// SKUs with the same matching, synonyms, filters, boosts and highlighting
+// as ProductRepository.Search. Text relevance is the number of matching
+// words, so the order of hits with equal boosts may differ from BM25.
+func (m *MemoryProductStore) Search(text string, query models.ProductQuery) ([]models.ProductSearchHit, int64, error) {
+	synonyms, ranking, err := m.searchConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+	terms := searchTerms(text)
+	_, highlightTerms := buildMatchQuery(terms, synonyms)
+
+	m.mu.RLock()
+	hits := []models.ProductSearchHit{}
+	for _, product := range m.state.find(liveMatching(query)) {
+		matches := searchMatches(product, terms, synonyms)
+		if matches == 0 {
+			continue
+		}
+		hits = append(hits, models.ProductSearchHit{
+			Product: product,
+			Rank:    -float64(matches),
+			Score:   float64(matches) * searchBoost(product, ranking, strings.TrimSpace(text)),
+		})
+	}
+	m.mu.RUnlock()
+
+	if len(query.Sort) > 0 {
+		sort.SliceStable(hits, func(i, j int) bool {
+			return compareProducts(hits[i].Product, hits[j].Product, query.Sort) < 0
+		})
+	} else {
+		sortByScore(hits)
+	}
+	total := int64(len(hits))
+	hits = page(hits, query.Limit, query.Offset)
+
+	// Highlight against the original text rather than the folded index
+	for i := range hits {
+		hits[i].NameHighlight = highlight(hits[i].Name, highlightTerms)
+		hits[i].DescriptionSnippet = snippet(hits[i].Description, highlightTerms, snippetWords)
+	}
+	return hits, total, nil
+}
+
+// SearchFacets computes the requested facets over all products matching a
+// full-text search and the listing filters
+func (m *MemoryProductStore) SearchFacets(text string, query models.ProductQuery, request models.FacetRequest) (models.Facets, error) {
+	synonyms, _, err := m.searchConfig()
+	if err != nil {
+		return nil, err
+	}
+	terms := searchTerms(text)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	products := m.state.find(func(product models.Product) bool {
+		return liveMatching(query)(product) && searchMatches(product, terms, synonyms) > 0
+	})
+	return memoryFacets(products, request)
+}
+
+// searchMatches returns how often the search terms occur in the indexed
+// columns of a product, or 0 unless every term, or one of its synonyms,
+// matches. Matching follows the FTS5 query built by buildMatchQuery: a term
+// is a prefix of a word, a synonym phrase is a run of words that ends with
+// a prefix, and both must lie within one column.
+func searchMatches(product models.Product, terms []string, synonyms map[string][]string) int {
+	if len(terms) == 0 {
+		return 0
+	}
+	columns := [][]string{
+		searchTerms(product.NameFolded),
+		searchTerms(product.DescriptionFolded),
+		searchTerms(product.SKU),
+	}
+
+	total := 0
+	for _, term := range terms {
+		alternatives := [][]string{{term}}
+		for _, synonym := range synonyms[term] {
+			alternatives = append(alternatives, searchTerms(synonym))
+		}
+
+		matches := 0
+		for _, phrase := range alternatives {
+			for _, words := range columns {
+				matches += phraseMatches(words, phrase)
+			}
+		}
+		if matches == 0 {
+			return 0
+		}
+		total += matches
+	}
+	return total
+}
+
+// phraseMatches counts the runs of words equal to the phrase, where the last
+// word of the phrase only needs to be a prefix
+func phraseMatches(words, phrase []string) int {
+	if len(phrase) == 0 {
+		return 0
+	}
+	count := 0
+	for start := 0; start+len(phrase) <= len(words); start++ {
+		match := true
+		for i, term := range phrase {
+			word := words[start+i]
+			if i == len(phrase)-1 {
+				match = strings.HasPrefix(word, term)
+			} else if word != term {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count
+}
+
+// searchBoost is the factor searchScoreExpr multiplies text relevance with
+func searchBoost(product models.Product, ranking models.SearchRanking, text string) float64 {
+	boost := 1.0
+	if product.Quantity > 0 {
+		boost *= 1 + ranking.InStockBoost
+	}
+
+	age := 0.0
+	if product.CreatedAt != nil {
+		age = math.Max(time.Since(*product.CreatedAt).Hours()/24, 0)
+	}
+	boost *= 1 + ranking.RecencyBoost/(1+age/ranking.RecencyHalfLifeDays)
+
+	// SQLite's UPPER only changes ASCII letters
+	if asciiUpper(product.SKU) == asciiUpper(text) {
+		boost *= 1 + ranking.ExactSKUBoost
+	}
+	return boost
+}
+
+// asciiUpper converts the ASCII letters of s to upper case
+func asciiUpper(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}, s)
+}
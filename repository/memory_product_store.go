@@ -0,0 +1,509 @@
+package repository
+
+import (
+	"errors"
+	"product-api/gtin"
+	"product-api/models"
+	"product-api/suggest"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryProductStore is a ProductStore that keeps products in memory. It is
+// safe for concurrent use and behaves like ProductRepository, except that
+// search relevance counts matching words instead of using BM25. Nothing is
+// persisted, so it suits tests and running the API without a database file.
+type MemoryProductStore struct {
+	mu          sync.RWMutex
+	state       memoryState
+	config      SearchConfigSource // Synonyms and ranking rules for search, may be nil
+	suggestions *suggest.Index     // Autocomplete index kept in sync with writes
+}
+
+// NewMemoryProductStore creates an empty in-memory product store. Search
+// reads its synonyms and ranking rules from config; a nil config means no
+// synonyms and the default ranking.
+func NewMemoryProductStore(config SearchConfigSource) *MemoryProductStore {
+	return &MemoryProductStore{
+		state:       memoryState{products: make(map[uint]models.Product)},
+		config:      config,
+		suggestions: suggest.New(),
+	}
+}
+
+// Create adds a new product. It returns a *ConflictError if the SKU or
+// barcode is already used by another live product.
+func (m *MemoryProductStore) Create(product *models.Product) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.state.create(product, time.Now()); err != nil {
+		return err
+	}
+
+	m.suggestions.Upsert(suggestEntry(*product))
+	return nil
+}
+
+// GetAll retrieves all live products
+func (m *MemoryProductStore) GetAll() ([]models.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.find(isLive), nil
+}
+
+// List retrieves a filtered, sorted page of products along with the total
+// number of products matching the filters
+func (m *MemoryProductStore) List(query models.ProductQuery) ([]models.Product, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	products := m.state.find(liveMatching(query))
+	sortProducts(products, query.Sort)
+	return page(products, query.Limit, query.Offset), int64(len(products)), nil
+}
+
+// Count returns the number of products matching the query's filters
+func (m *MemoryProductStore) Count(query models.ProductQuery) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.state.find(liveMatching(query)))), nil
+}
+
+// CountAll returns the number of products, including trashed ones
+func (m *MemoryProductStore) CountAll() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.state.products)), nil
+}
+
+// ListKeyset retrieves up to query.Limit filtered products in (updated_at, id)
+// order, starting strictly after the given position. A nil position starts
+// from the beginning. The boolean result reports whether more products follow.
+func (m *MemoryProductStore) ListKeyset(query models.ProductQuery, after *models.KeysetPosition) ([]models.Product, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	matches := liveMatching(query)
+	products := m.state.find(func(product models.Product) bool {
+		return matches(product) && (after == nil || afterPosition(product, *after))
+	})
+	sortProducts(products, []models.SortField{{Column: "products.updated_at"}})
+
+	hasMore := len(products) > query.Limit
+	return page(products, query.Limit, 0), hasMore, nil
+}
+
+// ListFacets computes the requested facets over all products matching the
+// listing filters
+func (m *MemoryProductStore) ListFacets(query models.ProductQuery, request models.FacetRequest) (models.Facets, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memoryFacets(m.state.find(liveMatching(query)), request)
+}
+
+// Export passes every product matching the query's filters to fn in its
+// sort order. The products are copied first, so fn may use the store;
+// Limit and Offset are ignored. Iteration stops at the first error from fn.
+func (m *MemoryProductStore) Export(query models.ProductQuery, fn func(models.Product) error) error {
+	m.mu.RLock()
+	products := m.state.find(liveMatching(query))
+	m.mu.RUnlock()
+
+	sortProducts(products, query.Sort)
+	for _, product := range products {
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByID retrieves a product by its ID
+func (m *MemoryProductStore) GetByID(id uint) (models.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	product, ok := m.state.live(id)
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	return product, nil
+}
+
+// GetBySKU retrieves the live product with the given SKU
+func (m *MemoryProductStore) GetBySKU(sku string) (models.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	product, ok := m.state.first(func(product models.Product) bool {
+		return isLive(product) && product.SKU == sku
+	})
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	return product, nil
+}
+
+// GetByBarcode retrieves the live product with the given barcode in any
+// supported format, see ProductRepository.GetByBarcode
+func (m *MemoryProductStore) GetByBarcode(code string) (models.Product, error) {
+	canonical, err := gtin.ToGTIN14(code)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	product, ok := m.state.first(func(product models.Product) bool {
+		return isLive(product) && product.GTIN == canonical
+	})
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	return product, nil
+}
+
+// GetVersion retrieves only the version and update time of a product
+func (m *MemoryProductStore) GetVersion(id uint) (models.ProductVersion, error) {
+	product, err := m.GetByID(id)
+	if err != nil {
+		return models.ProductVersion{}, err
+	}
+	return models.ProductVersion{ID: product.ID, Version: product.Version, UpdatedAt: product.UpdatedAt}, nil
+}
+
+// TableVersion returns the number of writes made to the store
+func (m *MemoryProductStore) TableVersion() (models.ProductTableVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return models.ProductTableVersion{Version: m.state.version}, nil
+}
+
+// Update writes all fields of a product if product.Version is still the
+// stored version, and increments it. See ProductRepository.Update.
+func (m *MemoryProductStore) Update(product *models.Product) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.state.update(product, time.Now()); err != nil {
+		return err
+	}
+
+	m.suggestions.Upsert(suggestEntry(*product))
+	return nil
+}
+
+// UpdateColumns writes only the given columns of a product, and the columns
+// derived from them. Like Update, the write is conditional on product.Version.
+func (m *MemoryProductStore) UpdateColumns(product *models.Product, columns []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.state.updateColumns(product, expandColumns(columns), time.Now()); err != nil {
+		return err
+	}
+
+	m.suggestions.Upsert(suggestEntry(*product))
+	return nil
+}
+
+// Delete moves a product to the trash. A non-zero version makes the delete
+// conditional on the stored version, like Update.
+func (m *MemoryProductStore) Delete(id uint, version uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.state.delete(id, version, time.Now()); err != nil {
+		return err
+	}
+
+	m.suggestions.Remove(id)
+	return nil
+}
+
+// UpsertBySKU creates, replaces or revives the product identified by
+// product.SKU, see ProductRepository.UpsertBySKU
+func (m *MemoryProductStore) UpsertBySKU(product *models.Product, version uint) (created bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	created, err = m.state.upsertBySKU(product, version, nil, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	m.suggestions.Upsert(suggestEntry(*product))
+	return created, nil
+}
+
+// ApplyBatch applies validated batch operations in order. In atomic mode
+// the outcomes end with the first failing operation and nothing is applied.
+// See ProductRepository.ApplyBatch.
+func (m *MemoryProductStore) ApplyBatch(operations []models.ProductBatchOperation, atomic bool) ([]BatchOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	outcomes := make([]BatchOutcome, 0, len(operations))
+	if atomic {
+		tx := m.state.clone()
+		for _, operation := range operations {
+			outcome := tx.applyBatchOperation(operation, now)
+			outcomes = append(outcomes, outcome)
+			if outcome.Err != nil {
+				return outcomes, nil
+			}
+		}
+		m.state = tx
+	} else {
+		for _, operation := range operations {
+			outcomes = append(outcomes, m.state.applyBatchOperation(operation, now))
+		}
+	}
+
+	for i, outcome := range outcomes {
+		if outcome.Err != nil {
+			continue
+		}
+		if operations[i].Op == models.BatchDelete {
+			m.suggestions.Remove(outcome.Product.ID)
+		} else {
+			m.suggestions.Upsert(suggestEntry(outcome.Product))
+		}
+	}
+	return outcomes, nil
+}
+
+// ImportBySKU upserts products by SKU like UpsertBySKU, all or nothing
+// except for rejected products, see ProductRepository.ImportBySKU. The
+// store is locked for the whole import, so progress must not use it.
+func (m *MemoryProductStore) ImportBySKU(products []models.Product, columns []string, dryRun bool, progress func(done int64) error) ([]ImportOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	tx := m.state.clone()
+	outcomes := make([]ImportOutcome, len(products))
+	for i := range products {
+		product := products[i]
+		created, err := tx.upsertBySKU(&product, 0, columns, now)
+		outcomes[i] = ImportOutcome{Product: product, Created: created, Err: err}
+		if progress != nil {
+			if err := progress(int64(i + 1)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if dryRun {
+		return outcomes, nil
+	}
+
+	m.state = tx
+	for _, outcome := range outcomes {
+		if outcome.Err == nil {
+			m.suggestions.Upsert(suggestEntry(outcome.Product))
+		}
+	}
+	return outcomes, nil
+}
+
+// ChangePrices applies a price change to every product matching the query's
+// filters in batches, see ProductRepository.ChangePrices. The store is
+// unlocked while progress runs.
+func (m *MemoryProductStore) ChangePrices(query models.ProductQuery, change models.PriceChange, progress func(done int64) error) (models.PriceChangeResult, error) {
+	var result models.PriceChangeResult
+	m.mu.RLock()
+	ids := productIDs(m.state.find(liveMatching(query)))
+	m.mu.RUnlock()
+
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(ids))
+		m.mu.Lock()
+		now := time.Now()
+		for _, id := range ids[start:end] {
+			product, ok := m.state.live(id)
+			if !ok {
+				continue
+			}
+			price := change.Apply(product.Price)
+			switch {
+			case price <= 0:
+				result.Skipped++
+				continue
+			case price == product.Price:
+				result.Unchanged++
+				continue
+			}
+			product.Price = price
+			product.Version++
+			product.UpdatedAt = timePtr(now)
+			m.state.put(product)
+			result.Updated++
+		}
+		m.mu.Unlock()
+
+		if progress != nil {
+			if err := progress(int64(end)); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Reindex recomputes the normalized text and GTIN fields of every product,
+// including trashed ones, and rebuilds the autocomplete index. A non-nil
+// progress function is called after each batch; an error from it stops the
+// reindex.
+func (m *MemoryProductStore) Reindex(progress func(done int64) error) error {
+	m.mu.RLock()
+	ids := productIDs(m.state.find(func(models.Product) bool { return true }))
+	m.mu.RUnlock()
+
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(ids))
+		m.mu.Lock()
+		for _, id := range ids[start:end] {
+			if product, ok := m.state.products[id]; ok {
+				product.Normalize()
+				m.state.put(product)
+			}
+		}
+		m.mu.Unlock()
+
+		if progress != nil {
+			if err := progress(int64(end)); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	live := m.state.find(isLive)
+	entries := make([]suggest.Entry, len(live))
+	for i, product := range live {
+		entries[i] = suggestEntry(product)
+	}
+	m.suggestions.Rebuild(entries)
+	return nil
+}
+
+// ListTrash retrieves a filtered, sorted page of trashed products along with
+// the total number of trashed products matching the filters. Without a
+// sort, the most recently deleted products come first.
+func (m *MemoryProductStore) ListTrash(query models.ProductQuery) ([]models.Product, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	products := m.state.find(func(product models.Product) bool {
+		return product.DeletedAt.Valid && matchesFilters(product, query)
+	})
+
+	order := query.Sort
+	if len(order) == 0 {
+		order = []models.SortField{{Column: "products.deleted_at", Desc: true}}
+	}
+	sortProducts(products, order)
+	return page(products, query.Limit, query.Offset), int64(len(products)), nil
+}
+
+// GetByIDUnscoped retrieves a product by its ID, including trashed products
+func (m *MemoryProductStore) GetByIDUnscoped(id uint) (models.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	product, ok := m.state.products[id]
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	return product, nil
+}
+
+// Restore moves a trashed product out of the trash and increments its
+// version. It returns ErrNotFound if the product is not in the trash and a
+// *ConflictError if a live product has taken its SKU or barcode.
+func (m *MemoryProductStore) Restore(id uint) (models.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	product, err := m.state.restore(id, time.Now())
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	m.suggestions.Upsert(suggestEntry(product))
+	return product, nil
+}
+
+// DeletePermanently removes a product, whether it is live or in the trash.
+// A non-zero version makes the delete conditional on the stored version.
+func (m *MemoryProductStore) DeletePermanently(id uint, version uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.state.deletePermanently(id, version); err != nil {
+		return err
+	}
+
+	m.suggestions.Remove(id)
+	return nil
+}
+
+// PurgeTrash permanently removes products that were trashed before the
+// given time and returns how many were removed
+func (m *MemoryProductStore) PurgeTrash(before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var purged int64
+	for id, product := range m.state.products {
+		if product.DeletedAt.Valid && product.DeletedAt.Time.Before(before) {
+			m.state.drop(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Suggest returns up to limit products whose name or SKU starts with the
+// prefix, tolerating typos in names
+func (m *MemoryProductStore) Suggest(prefix string, limit int) ([]models.ProductSuggestion, error) {
+	return suggestionsOf(m.suggestions.Suggest(prefix, limit)), nil
+}
+
+// searchConfig reads the synonyms and ranking rules used by search
+func (m *MemoryProductStore) searchConfig() (map[string][]string, models.SearchRanking, error) {
+	if m.config == nil {
+		return nil, models.DefaultSearchRanking(), nil
+	}
+	synonyms, err := m.config.ListSynonyms()
+	if err != nil {
+		return nil, models.SearchRanking{}, err
+	}
+	ranking, err := m.config.GetRanking()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, models.SearchRanking{}, err
+	}
+	if err != nil {
+		ranking = models.DefaultSearchRanking()
+	}
+
+	dictionary := make(map[string][]string, len(synonyms))
+	for _, s := range synonyms {
+		dictionary[s.Term] = s.Synonyms
+	}
+	return dictionary, ranking, nil
+}
+
+// productIDs returns the IDs of the products in order
+func productIDs(products []models.Product) []uint {
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	return ids
+}
+
+// suggestEntry is the autocomplete entry of a product
+func suggestEntry(product models.Product) suggest.Entry {
+	return suggest.Entry{ID: product.ID, Name: product.Name, SKU: product.SKU}
+}
+
+// sortByScore orders search hits by descending score, then by ID
+func sortByScore(hits []models.ProductSearchHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"product-api/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// memoryState holds the products of a MemoryProductStore. Its write methods
+// mirror the SQL helpers of the same name and check everything before they
+// change anything, so a failed write leaves the state as it was. Atomic
+// operations work on a clone that replaces the state when they succeed.
+type memoryState struct {
+	products map[uint]models.Product // All products; trashed ones have DeletedAt set
+	lastID   uint                    // Highest ID assigned so far, IDs are never reused
+	version  int64                   // Incremented by every write, see TableVersion
+}
+
+// clone returns a copy of the state that can be changed independently.
+// Stored products are replaced rather than modified, so copying the map is enough.
+func (s *memoryState) clone() memoryState {
+	return memoryState{products: maps.Clone(s.products), lastID: s.lastID, version: s.version}
+}
+
+// put stores a new or changed product
+func (s *memoryState) put(product models.Product) {
+	s.products[product.ID] = product
+	s.version++
+}
+
+// drop removes a product for good
+func (s *memoryState) drop(id uint) {
+	delete(s.products, id)
+	s.version++
+}
+
+// live returns the product with the ID unless it is missing or trashed
+func (s *memoryState) live(id uint) (models.Product, bool) {
+	product, ok := s.products[id]
+	return product, ok && !product.DeletedAt.Valid
+}
+
+// find returns the products for which keep returns true, in ID order
+func (s *memoryState) find(keep func(models.Product) bool) []models.Product {
+	products := []models.Product{}
+	for _, product := range s.products {
+		if keep(product) {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products
+}
+
+// first returns the product with the lowest ID for which keep returns true
+func (s *memoryState) first(keep func(models.Product) bool) (models.Product, bool) {
+	var found models.Product
+	ok := false
+	for _, product := range s.products {
+		if keep(product) && (!ok || product.ID < found.ID) {
+			found, ok = product, true
+		}
+	}
+	return found, ok
+}
+
+// findConflict looks for another live product with the same SKU or barcode
+func (s *memoryState) findConflict(product *models.Product) *ConflictError {
+	canonical := product.CanonicalGTIN()
+	fields := []struct {
+		name    string
+		value   string
+		matches func(other models.Product) bool
+	}{
+		{"sku", product.SKU, func(other models.Product) bool { return product.SKU != "" && other.SKU == product.SKU }},
+		{"barcode", product.Barcode, func(other models.Product) bool { return canonical != "" && other.GTIN == canonical }},
+	}
+	for _, field := range fields {
+		other, ok := s.first(func(other models.Product) bool {
+			return !other.DeletedAt.Valid && other.ID != product.ID && field.matches(other)
+		})
+		if ok {
+			return &ConflictError{Field: field.name, Value: field.value, ConflictingID: other.ID}
+		}
+	}
+	return nil
+}
+
+// ensureUnique returns a ConflictError if the product's SKU or barcode is
+// already used by another live product
+func (s *memoryState) ensureUnique(product *models.Product) error {
+	if conflict := s.findConflict(product); conflict != nil {
+		return conflict
+	}
+	return nil
+}
+
+// current returns the live product with the ID, or the error missingOrStale
+// gives when it is missing or does not have the expected version
+func (s *memoryState) current(id uint, version uint) (models.Product, error) {
+	existing, ok := s.live(id)
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	if existing.Version != version {
+		return models.Product{}, ErrVersionMismatch
+	}
+	return existing, nil
+}
+
+// create inserts a product, filling in the fields GORM fills on insert
+func (s *memoryState) create(product *models.Product, now time.Time) error {
+	if err := s.ensureUnique(product); err != nil {
+		return err
+	}
+	if product.ID == 0 {
+		product.ID = s.lastID + 1
+	} else if _, ok := s.products[product.ID]; ok {
+		return fmt.Errorf("%w: product %d already exists", ErrConflict, product.ID)
+	}
+
+	if product.Version == 0 {
+		product.Version = 1
+	}
+	if product.CreatedAt == nil {
+		product.CreatedAt = timePtr(now)
+	}
+	if product.UpdatedAt == nil {
+		product.UpdatedAt = timePtr(now)
+	}
+	product.Normalize()
+	s.put(*product)
+	s.lastID = max(s.lastID, product.ID)
+	return nil
+}
+
+// update writes all fields of a live product except its creation time
+func (s *memoryState) update(product *models.Product, now time.Time) error {
+	if err := s.ensureUnique(product); err != nil {
+		return err
+	}
+	existing, err := s.current(product.ID, product.Version)
+	if err != nil {
+		return err
+	}
+
+	product.Version++
+	product.UpdatedAt = timePtr(now)
+	product.Normalize()
+	stored := *product
+	stored.CreatedAt = existing.CreatedAt
+	stored.DeletedAt = existing.DeletedAt
+	s.put(stored)
+	return nil
+}
+
+// updateColumns writes the given columns, already expanded by expandColumns,
+// and the update time of a live product
+func (s *memoryState) updateColumns(product *models.Product, columns []string, now time.Time) error {
+	if err := s.ensureUnique(product); err != nil {
+		return err
+	}
+	stored, err := s.current(product.ID, product.Version)
+	if err != nil {
+		return err
+	}
+
+	product.Version++
+	product.UpdatedAt = timePtr(now)
+	product.Normalize()
+	for _, column := range columns {
+		copyColumn(&stored, product, column)
+	}
+	stored.UpdatedAt = product.UpdatedAt
+	s.put(stored)
+	return nil
+}
+
+// copyColumn copies the field stored in a products column from src to dst
+func copyColumn(dst, src *models.Product, column string) {
+	switch column {
+	case "name":
+		dst.Name = src.Name
+	case "description":
+		dst.Description = src.Description
+	case "price":
+		dst.Price = src.Price
+	case "quantity":
+		dst.Quantity = src.Quantity
+	case "sku":
+		dst.SKU = src.SKU
+	case "barcode":
+		dst.Barcode = src.Barcode
+	case "barcode_format":
+		dst.BarcodeFormat = src.BarcodeFormat
+	case "gtin":
+		dst.GTIN = src.GTIN
+	case "name_folded":
+		dst.NameFolded = src.NameFolded
+	case "description_folded":
+		dst.DescriptionFolded = src.DescriptionFolded
+	case "name_sort_key":
+		dst.NameSortKey = src.NameSortKey
+	case "version":
+		dst.Version = src.Version
+	}
+}
+
+// delete moves a live product to the trash. A non-zero version must match.
+func (s *memoryState) delete(id uint, version uint, now time.Time) error {
+	product, ok := s.live(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if version != 0 && product.Version != version {
+		return ErrVersionMismatch
+	}
+	product.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	s.put(product)
+	return nil
+}
+
+// deletePermanently removes a live or trashed product. A non-zero version must match.
+func (s *memoryState) deletePermanently(id uint, version uint) error {
+	product, ok := s.products[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if version != 0 && product.Version != version {
+		return ErrVersionMismatch
+	}
+	s.drop(id)
+	return nil
+}
+
+// restore moves a product out of the trash and increments its version
+func (s *memoryState) restore(id uint, now time.Time) (models.Product, error) {
+	product, ok := s.products[id]
+	if !ok || !product.DeletedAt.Valid {
+		return models.Product{}, ErrNotFound
+	}
+	if err := s.ensureUnique(&product); err != nil {
+		return models.Product{}, err
+	}
+
+	product.DeletedAt = gorm.DeletedAt{}
+	product.Version++
+	product.UpdatedAt = timePtr(now)
+	s.put(product)
+	return product, nil
+}
+
+// upsertBySKU implements UpsertBySKU, see upsertBySKU
+func (s *memoryState) upsertBySKU(product *models.Product, version uint, columns []string, now time.Time) (bool, error) {
+	// Prefer the live product, then the most recently deleted one
+	var existing models.Product
+	found := false
+	for _, candidate := range s.find(func(other models.Product) bool { return other.SKU == product.SKU }) {
+		if !found || preferForUpsert(candidate, existing) {
+			existing, found = candidate, true
+		}
+	}
+	if !found {
+		if version != 0 {
+			return false, ErrNotFound
+		}
+		if err := s.create(product, now); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	revived := existing.DeletedAt.Valid
+	if version != 0 {
+		if revived {
+			return false, ErrNotFound
+		}
+		if existing.Version != version {
+			return false, ErrVersionMismatch
+		}
+	}
+
+	if columns != nil {
+		product.KeepColumns(existing, columns)
+	}
+
+	// Nothing to write when a live product already has these values
+	update := models.NewProductUpdateDTO(*product)
+	if !revived && len(update.ChangedColumns(existing)) == 0 {
+		*product = existing
+		return false, nil
+	}
+
+	product.ID = existing.ID
+	product.CreatedAt = existing.CreatedAt
+	product.Version = existing.Version + 1
+	if err := s.ensureUnique(product); err != nil {
+		return false, err
+	}
+
+	// Every field is written, which also clears DeletedAt of a revived product
+	product.UpdatedAt = timePtr(now)
+	product.Normalize()
+	s.put(*product)
+	return revived, nil
+}
+
+// preferForUpsert reports whether upsertBySKU picks a over b: live products
+// first, then the most recently deleted, then the lowest ID. Candidates come
+// in ID order, so ties keep the earlier one.
+func preferForUpsert(a, b models.Product) bool {
+	if a.DeletedAt.Valid != b.DeletedAt.Valid {
+		return !a.DeletedAt.Valid
+	}
+	return a.DeletedAt.Valid && a.DeletedAt.Time.After(b.DeletedAt.Time)
+}
+
+// applyBatchOperation runs one batch operation, see applyBatchOperation
+func (s *memoryState) applyBatchOperation(operation models.ProductBatchOperation, now time.Time) BatchOutcome {
+	switch operation.Op {
+	case models.BatchCreate:
+		create := models.ProductCreateDTO(*operation.Product)
+		product := create.ToProduct()
+		err := s.create(&product, now)
+		return BatchOutcome{Product: product, Err: err}
+
+	case models.BatchUpdate:
+		product, ok := s.live(operation.ID)
+		if !ok {
+			return BatchOutcome{Product: models.Product{ID: operation.ID}, Err: ErrNotFound}
+		}
+		if operation.Version != 0 && product.Version != operation.Version {
+			return BatchOutcome{Product: product, Err: ErrVersionMismatch}
+		}
+		operation.Product.ApplyToProduct(&product)
+		err := s.update(&product, now)
+		return BatchOutcome{Product: product, Err: err}
+
+	default:
+		err := s.delete(operation.ID, operation.Version, now)
+		return BatchOutcome{Product: models.Product{ID: operation.ID}, Err: err}
+	}
+}
+
+// timePtr returns a pointer to a copy of t, so stored products never share
+// a timestamp
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// BatchOutcome is the result of applying one batch operation
+type BatchOutcome struct {
+	Product models.Product // Created or updated product, or the ID of a deleted one
+	Err     error          // Why the operation failed, nil on success
+}
+
+// ApplyBatch applies validated batch operations in order. In atomic mode all
+// operations run in one transaction that stops and rolls back at the first
+// failure, so the outcomes end with the failing operation and nothing is
+// applied. Otherwise every operation runs in its own transaction and all
+// outcomes are returned. Errors are the same as those of Create, Update and
+// Delete.
+func (r *ProductRepository) ApplyBatch(operations []models.ProductBatchOperation, atomic bool) ([]BatchOutcome, error) {
+	outcomes := make([]BatchOutcome, 0, len(operations))
+
+	if atomic {
+		rolledBack := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			for _, operation := range operations {
+				outcome := applyBatchOperation(tx, operation)
+				outcomes = append(outcomes, outcome)
+				if outcome.Err != nil {
+					rolledBack = true
+					return outcome.Err
+				}
+			}
+			return nil
+		})
+		if rolledBack {
+			return outcomes, nil
+		}
+		if err != nil {
+			return nil, translate(err)
+		}
+	} else {
+		for _, operation := range operations {
+			var outcome BatchOutcome
+			err := r.db.Transaction(func(tx *gorm.DB) error {
+				outcome = applyBatchOperation(tx, operation)
+				return outcome.Err
+			})
+			if outcome.Err == nil && err != nil {
+				outcome.Err = translate(err)
+			}
+			outcomes = append(outcomes, outcome)
+		}
+	}
+
+	// Keep the autocomplete index in sync with what was committed
+	for i, outcome := range outcomes {
+		if outcome.Err != nil {
+			continue
+		}
+		if operations[i].Op == models.BatchDelete {
+			r.suggestions.remove(outcome.Product.ID)
+		} else {
+			r.suggestions.upsert(outcome.Product)
+		}
+	}
+	return outcomes, nil
+}
+
+// applyBatchOperation runs one operation using tx
+func applyBatchOperation(tx *gorm.DB, operation models.ProductBatchOperation) BatchOutcome {
+	switch operation.Op {
+	case models.BatchCreate:
+		// Create and update DTOs have the same fields
+		create := models.ProductCreateDTO(*operation.Product)
+		product := create.ToProduct()
+		err := createProduct(tx, &product)
+		return BatchOutcome{Product: product, Err: err}
+
+	case models.BatchUpdate:
+		var product models.Product
+		if err := tx.First(&product, operation.ID).Error; err != nil {
+			return BatchOutcome{Product: models.Product{ID: operation.ID}, Err: translate(err)}
+		}
+		if operation.Version != 0 && product.Version != operation.Version {
+			return BatchOutcome{Product: product, Err: ErrVersionMismatch}
+		}
+		operation.Product.ApplyToProduct(&product)
+		err := updateProduct(tx, &product)
+		return BatchOutcome{Product: product, Err: err}
+
+	default:
+		err := deleteProduct(tx, operation.ID, operation.Version)
+		return BatchOutcome{Product: models.Product{ID: operation.ID}, Err: err}
+	}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"product-api/database"
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// bulkBatchSize is the number of products changed per transaction by bulk
+// operations, so other writers are not blocked for the whole operation
+const bulkBatchSize = 500
+
+// ChangePrices applies a price change to every product matching the query's
+// filters. Products are changed in batches, each committed on its own, and
+// every changed product gets a new version. Products whose new price would
+// not be positive are skipped. A non-nil progress function is called after
+// each batch; an error from it stops the change, leaving the committed
+// batches applied.
+func (r *ProductRepository) ChangePrices(query models.ProductQuery, change models.PriceChange, progress func(done int64) error) (models.PriceChangeResult, error) {
+	var result models.PriceChangeResult
+	var ids []uint
+	err := applyProductFilters(r.db.Model(&models.Product{}), query).
+		Order("products.id").
+		Pluck("products.id", &ids).Error
+	if err != nil {
+		return result, translate(err)
+	}
+
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(ids))
+		batch := models.PriceChangeResult{}
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			var products []models.Product
+			if err := tx.Select("id", "price").Where("id IN ?", ids[start:end]).Find(&products).Error; err != nil {
+				return err
+			}
+
+			now := tx.NowFunc()
+			for _, product := range products {
+				price := change.Apply(product.Price)
+				switch {
+				case price <= 0:
+					batch.Skipped++
+					continue
+				case price == product.Price:
+					batch.Unchanged++
+					continue
+				}
+				err := tx.Model(&models.Product{}).Where("id = ?", product.ID).UpdateColumns(map[string]interface{}{
+					"price":      price,
+					"version":    gorm.Expr("version + 1"),
+					"updated_at": now,
+				}).Error
+				if err != nil {
+					return err
+				}
+				batch.Updated++
+			}
+			return nil
+		})
+		if err != nil {
+			return result, translate(err)
+		}
+
+		result.Updated += batch.Updated
+		result.Unchanged += batch.Unchanged
+		result.Skipped += batch.Skipped
+		if progress != nil {
+			if err := progress(int64(end)); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Reindex recomputes the normalized text and GTIN columns of every product,
+// including trashed ones, then rebuilds the search index and reloads the
+// autocomplete index. A non-nil progress function is called after each
+// batch; an error from it stops the reindex.
+func (r *ProductRepository) Reindex(progress func(done int64) error) error {
+	var done int64
+	var products []models.Product
+	result := r.db.Unscoped().FindInBatches(&products, bulkBatchSize, func(tx *gorm.DB, batch int) error {
+		for i := range products {
+			products[i].Normalize()
+			err := r.db.Unscoped().Model(&models.Product{}).Where("id = ?", products[i].ID).UpdateColumns(map[string]interface{}{
+				"name_folded":        products[i].NameFolded,
+				"description_folded": products[i].DescriptionFolded,
+				"name_sort_key":      products[i].NameSortKey,
+				"gtin":               products[i].GTIN,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		done += int64(len(products))
+		if progress != nil {
+			return progress(done)
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+
+	if err := database.RebuildSearchIndex(r.db); err != nil {
+		return translate(err)
+	}
+	r.suggestions.reset()
+	return nil
+}
+
+// CountAll returns the number of products, including trashed ones
+func (r *ProductRepository) CountAll() (int64, error) {
+	var total int64
+	result := r.db.Unscoped().Model(&models.Product{}).Count(&total)
+	return total, translate(result.Error)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// ConflictError reports that a product cannot be saved because another live
+// product already uses the same unique value
+type ConflictError struct {
+	Field         string // JSON name of the conflicting field, "sku" or "barcode"
+	Value         string // The duplicated value
+	ConflictingID uint   // ID of the product that already uses the value
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q is already used by product %d", e.Field, e.Value, e.ConflictingID)
+}
+
+// Is makes a ConflictError match ErrConflict
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// findConflict looks for another live product with the same SKU or barcode.
+// It returns nil when the product's unique values are free.
+func findConflict(db *gorm.DB, product *models.Product) (*ConflictError, error) {
+	// Barcodes are compared by GTIN so that every form of a code conflicts
+	fields := []struct {
+		name   string
+		column string
+		key    string
+		value  string
+	}{
+		{"sku", "sku", product.SKU, product.SKU},
+		{"barcode", "gtin", product.CanonicalGTIN(), product.Barcode},
+	}
+	for _, field := range fields {
+		if field.key == "" {
+			continue
+		}
+		var ids []uint
+		err := db.Model(&models.Product{}).
+			Where(field.column+" = ? AND id <> ?", field.key, product.ID).
+			Limit(1).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return nil, translate(err)
+		}
+		if len(ids) > 0 {
+			return &ConflictError{Field: field.name, Value: field.value, ConflictingID: ids[0]}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ensureUnique returns a ConflictError if the product's SKU or barcode is
+// already used by another live product
+func ensureUnique(db *gorm.DB, product *models.Product) error {
+	conflict, err := findConflict(db, product)
+	if err != nil {
+		return err
+	}
+	if conflict != nil {
+		return conflict
+	}
+	return nil
+}
+
+// uniqueViolation converts a write error caused by the unique indexes, for
+// example when a concurrent request won the race, into a ConflictError.
+// Other errors are translated like any database error.
+func uniqueViolation(db *gorm.DB, product *models.Product, err error) error {
+	if !isUniqueViolation(err) {
+		return translate(err)
+	}
+	conflict, lookupErr := findConflict(db, product)
+	if lookupErr != nil || conflict == nil {
+		return translate(err)
+	}
+	return conflict
+}
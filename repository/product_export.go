@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"product-api/models"
+)
+
+// Export reads every product matching the query's filters in its sort
+// order and passes them to fn one at a time. Rows come straight from a
+// database cursor, so the catalog is never held in memory; Limit and Offset
+// are ignored. Iteration stops at the first error returned by fn.
+func (r *ProductRepository) Export(query models.ProductQuery, fn func(models.Product) error) error {
+	db := applyProductFilters(r.db.Model(&models.Product{}), query)
+	rows, err := applyProductSort(db, query.Sort).Rows()
+	if err != nil {
+		return translate(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product models.Product
+		if err := r.db.ScanRows(rows, &product); err != nil {
+			return translate(err)
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"fmt"
+	"product-api/models"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ListFacets computes the requested facets over all products matching the
+// listing filters
+func (r *ProductRepository) ListFacets(query models.ProductQuery, request models.FacetRequest) (models.Facets, error) {
+	return computeFacets(func() *gorm.DB {
+		return applyProductFilters(r.db.Model(&models.Product{}), query)
+	}, request)
+}
+
+// SearchFacets computes the requested facets over all products matching a
+// full-text search and the listing filters
+func (r *ProductRepository) SearchFacets(text string, query models.ProductQuery, request models.FacetRequest) (models.Facets, error) {
+	synonyms, err := loadSynonyms(r.db)
+	if err != nil {
+		return nil, err
+	}
+	match, _ := buildMatchQuery(searchTerms(text), synonyms)
+	if match == "" {
+		return computeFacets(nil, request)
+	}
+	return computeFacets(func() *gorm.DB {
+		return searchScope(r.db, match, query)
+	}, request)
+}
+
+// computeFacets runs one aggregate query per requested facet. scope returns
+// a fresh query over the matching products; a nil scope means nothing matches.
+func computeFacets(scope func() *gorm.DB, request models.FacetRequest) (models.Facets, error) {
+	facets := make(models.Facets, len(request.Names))
+	for _, name := range request.Names {
+		var buckets []models.FacetBucket
+		var err error
+		switch name {
+		case models.FacetPrice:
+			buckets, err = priceFacet(scope, request.PriceBuckets)
+		case models.FacetStock:
+			buckets, err = stockFacet(scope)
+		default:
+			err = fmt.Errorf("unknown facet %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		facets[name] = buckets
+	}
+	return facets, nil
+}
+
+// facetCount is a row of a grouped count query
+type facetCount struct {
+	Bucket int
+	Count  int64
+}
+
+// groupCounts counts matching products per value of a bucket expression
+func groupCounts(scope func() *gorm.DB, bucketExpr string, args ...interface{}) (map[int]int64, error) {
+	counts := make(map[int]int64)
+	if scope == nil {
+		return counts, nil
+	}
+
+	var rows []facetCount
+	err := scope().
+		Select(bucketExpr+" AS bucket, COUNT(*) AS count", args...).
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	for _, row := range rows {
+		counts[row.Bucket] = row.Count
+	}
+	return counts, nil
+}
+
+// priceFacet counts products per price range. Boundaries b1 < b2 < ... < bn
+// produce the buckets *-b1, b1-b2, ..., bn-*.
+func priceFacet(scope func() *gorm.DB, boundaries []float64) ([]models.FacetBucket, error) {
+	var expr strings.Builder
+	args := make([]interface{}, 0, len(boundaries))
+	expr.WriteString("CASE")
+	for i, boundary := range boundaries {
+		fmt.Fprintf(&expr, " WHEN products.price < ? THEN %d", i)
+		args = append(args, boundary)
+	}
+	fmt.Fprintf(&expr, " ELSE %d END", len(boundaries))
+
+	counts, err := groupCounts(scope, expr.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	return priceBuckets(boundaries, counts), nil
+}
+
+// priceBuckets builds the price facet from the number of products per
+// bucket, keyed by the index of the bucket
+func priceBuckets(boundaries []float64, counts map[int]int64) []models.FacetBucket {
+	buckets := make([]models.FacetBucket, len(boundaries)+1)
+	for i := range buckets {
+		bucket := models.FacetBucket{Count: counts[i]}
+		from, to := "*", "*"
+		if i > 0 {
+			bucket.From = &boundaries[i-1]
+			from = strconv.FormatFloat(boundaries[i-1], 'f', -1, 64)
+		}
+		if i < len(boundaries) {
+			bucket.To = &boundaries[i]
+			to = strconv.FormatFloat(boundaries[i], 'f', -1, 64)
+		}
+		bucket.Key = from + "-" + to
+		buckets[i] = bucket
+	}
+	return buckets
+}
+
+// stockFacet counts in-stock and out-of-stock products
+func stockFacet(scope func() *gorm.DB) ([]models.FacetBucket, error) {
+	counts, err := groupCounts(scope, "CASE WHEN products.quantity > 0 THEN 1 ELSE 0 END")
+	if err != nil {
+		return nil, err
+	}
+	return stockBuckets(counts), nil
+}
+
+// stockBuckets builds the stock facet from the number of in-stock (1) and
+// out-of-stock (0) products
+func stockBuckets(counts map[int]int64) []models.FacetBucket {
+	return []models.FacetBucket{
+		{Key: "in_stock", Count: counts[1]},
+		{Key: "out_of_stock", Count: counts[0]},
+	}
+}
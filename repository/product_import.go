@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// errDryRun rolls back the transaction of a dry-run import
+var errDryRun = errors.New("dry run")
+
+// ImportOutcome is the result of importing one product
+type ImportOutcome struct {
+	Product models.Product // The product as stored
+	Created bool           // Whether the product was created or revived rather than replaced
+	Err     error          // Why the product was rejected, nil on success
+}
+
+// ImportBySKU upserts products by SKU like UpsertBySKU, except that existing
+// products only have the given columns replaced, named like ChangedColumns;
+// nil replaces all of them. All products are
+// imported in one transaction with a savepoint per product, so a rejected
+// product does not affect the others and other clients never see a half
+// finished import. A dry run performs the same checks and writes, then rolls
+// everything back, so its outcomes are exactly what a real import would do.
+// A non-nil progress function is called after every product; an error from
+// it rolls back the whole import.
+func (r *ProductRepository) ImportBySKU(products []models.Product, columns []string, dryRun bool, progress func(done int64) error) ([]ImportOutcome, error) {
+	outcomes := make([]ImportOutcome, len(products))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range products {
+			product := products[i]
+			var created bool
+			err := tx.Transaction(func(row *gorm.DB) error {
+				var err error
+				created, err = upsertBySKU(row, &product, 0, columns)
+				return err
+			})
+			outcomes[i] = ImportOutcome{Product: product, Created: created, Err: err}
+			if progress != nil {
+				if err := progress(int64(i + 1)); err != nil {
+					return err
+				}
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, translate(err)
+	}
+
+	if !dryRun {
+		for _, outcome := range outcomes {
+			if outcome.Err == nil {
+				r.suggestions.upsert(outcome.Product)
+			}
+		}
+	}
+	return outcomes, nil
+}
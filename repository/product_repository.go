@@ -1,67 +1,346 @@
 package repository
 
 import (
-	"errors"
-	"product-api/database"
+	"product-api/gtin"
 	"product-api/models"
+	"product-api/normalize"
+	"product-api/suggest"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
-// ProductRepository handles database operations for products
-type ProductRepository struct{}
+// ProductRepository is the ProductStore backed by SQLite through GORM
+type ProductRepository struct {
+	db          *gorm.DB      // Database with the products table and search index
+	suggestions *suggestIndex // Autocomplete index kept in sync with writes
+}
 
-// NewProductRepository creates a new product repository
-func NewProductRepository() *ProductRepository {
-	return &ProductRepository{}
+// NewProductRepository creates a product repository on db, which must have
+// been migrated by the database package
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{
+		db:          db,
+		suggestions: &suggestIndex{index: suggest.New()},
+	}
 }
 
-// Create adds a new product to the database
+// Create adds a new product to the database. It returns a *ConflictError
+// if the SKU or barcode is already used by another live product.
 func (r *ProductRepository) Create(product *models.Product) error {
-	result := database.DB.Create(product)
-	return result.Error
+	if err := createProduct(r.db, product); err != nil {
+		return err
+	}
+
+	r.suggestions.upsert(*product)
+	return nil
+}
+
+// createProduct inserts a product using db, which may be a transaction
+func createProduct(db *gorm.DB, product *models.Product) error {
+	if err := ensureUnique(db, product); err != nil {
+		return err
+	}
+
+	result := db.Create(product)
+	if result.Error != nil {
+		return uniqueViolation(db, product, result.Error)
+	}
+	return nil
 }
 
 // GetAll retrieves all products from the database
 func (r *ProductRepository) GetAll() ([]models.Product, error) {
 	var products []models.Product
-	result := database.DB.Find(&products)
-	return products, result.Error
+	result := r.db.Find(&products)
+	return products, translate(result.Error)
+}
+
+// List retrieves a filtered, sorted page of products along with
+// the total number of products matching the filters
+func (r *ProductRepository) List(query models.ProductQuery) ([]models.Product, int64, error) {
+	var total int64
+	filtered := applyProductFilters(r.db.Model(&models.Product{}), query)
+	if err := filtered.Count(&total).Error; err != nil {
+		return nil, 0, translate(err)
+	}
+
+	var products []models.Product
+	result := applyProductSort(applyProductFilters(r.db, query), query.Sort).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&products)
+	return products, total, translate(result.Error)
+}
+
+// Count returns the number of products matching the query's filters
+func (r *ProductRepository) Count(query models.ProductQuery) (int64, error) {
+	var total int64
+	result := applyProductFilters(r.db.Model(&models.Product{}), query).Count(&total)
+	return total, translate(result.Error)
+}
+
+// ListKeyset retrieves up to query.Limit filtered products in (updated_at, id)
+// order, starting strictly after the given position. A nil position starts
+// from the beginning. The boolean result reports whether more products follow.
+func (r *ProductRepository) ListKeyset(query models.ProductQuery, after *models.KeysetPosition) ([]models.Product, bool, error) {
+	db := applyProductFilters(r.db, query)
+	if after != nil {
+		db = db.Where("(updated_at, id) > (?, ?)", after.UpdatedAt.UTC(), after.ID)
+	}
+
+	// Fetch one extra row to find out whether another page exists
+	var products []models.Product
+	result := db.Order("updated_at").Order("id").Limit(query.Limit + 1).Find(&products)
+	if result.Error != nil {
+		return nil, false, translate(result.Error)
+	}
+
+	hasMore := len(products) > query.Limit
+	if hasMore {
+		products = products[:query.Limit]
+	}
+	return products, hasMore, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, so user input is
+// matched literally with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike returns s as a LIKE pattern matching only s itself
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// applyProductFilters adds the WHERE clauses described by the query.
+// Columns are qualified so the filters also work when products is joined.
+func applyProductFilters(db *gorm.DB, query models.ProductQuery) *gorm.DB {
+	if query.Name != "" {
+		db = db.Where(`products.name_folded LIKE ? ESCAPE '\'`, "%"+escapeLike(normalize.Fold(query.Name))+"%")
+	}
+	if query.SKU != "" {
+		db = db.Where("products.sku = ?", query.SKU)
+	}
+	if query.Barcode != "" {
+		// Any valid form of a barcode matches its canonical GTIN-14
+		if code, err := gtin.ToGTIN14(query.Barcode); err == nil {
+			db = db.Where("products.gtin = ?", code)
+		} else {
+			db = db.Where("products.barcode = ?", query.Barcode)
+		}
+	}
+	if query.MinPrice != nil {
+		db = db.Where("products.price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		db = db.Where("products.price <= ?", *query.MaxPrice)
+	}
+	if query.MinQuantity != nil {
+		db = db.Where("products.quantity >= ?", *query.MinQuantity)
+	}
+	if query.MaxQuantity != nil {
+		db = db.Where("products.quantity <= ?", *query.MaxQuantity)
+	}
+	return db
+}
+
+// applyProductSort adds the ORDER BY clauses, falling back to ID order
+// so that pages are stable
+func applyProductSort(db *gorm.DB, sort []models.SortField) *gorm.DB {
+	for _, field := range sort {
+		order := field.Column
+		if field.Desc {
+			order += " DESC"
+		}
+		db = db.Order(order)
+	}
+	return db.Order("products.id")
 }
 
 // GetByID retrieves a product by its ID
 func (r *ProductRepository) GetByID(id uint) (models.Product, error) {
 	var product models.Product
-	result := database.DB.First(&product, id)
+	result := r.db.First(&product, id)
 	if result.Error != nil {
-		return models.Product{}, result.Error
+		return models.Product{}, translate(result.Error)
 	}
 	return product, nil
 }
 
-// Update updates a product in the database
-func (r *ProductRepository) Update(product models.Product) error {
-	result := database.DB.Save(&product)
+// GetBySKU retrieves the live product with the given SKU
+func (r *ProductRepository) GetBySKU(sku string) (models.Product, error) {
+	var product models.Product
+	result := r.db.Where("sku = ?", sku).Order("id").First(&product)
 	if result.Error != nil {
-		return result.Error
+		return models.Product{}, translate(result.Error)
+	}
+	return product, nil
+}
+
+// GetByBarcode retrieves the live product with the given barcode. The code
+// may be in any supported format and is matched by its GTIN-14, so an
+// EAN-13 finds a product stored with the equivalent UPC-A. An invalid code
+// returns the gtin validation error.
+func (r *ProductRepository) GetByBarcode(code string) (models.Product, error) {
+	canonical, err := gtin.ToGTIN14(code)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	var product models.Product
+	result := r.db.Where("gtin = ?", canonical).Order("id").First(&product)
+	if result.Error != nil {
+		return models.Product{}, translate(result.Error)
+	}
+	return product, nil
+}
+
+// GetVersion retrieves only the version and update time of a product
+func (r *ProductRepository) GetVersion(id uint) (models.ProductVersion, error) {
+	var version models.ProductVersion
+	result := r.db.Model(&models.Product{}).
+		Select("id, version, updated_at").
+		Where("id = ?", id).
+		Take(&version)
+	if result.Error != nil {
+		return models.ProductVersion{}, translate(result.Error)
+	}
+	return version, nil
+}
+
+// TableVersion reads the write counter of the products table, which triggers
+// increment on every insert, update and delete, so it is a single-row lookup
+func (r *ProductRepository) TableVersion() (models.ProductTableVersion, error) {
+	var version models.ProductTableVersion
+	result := r.db.Raw("SELECT version FROM products_version").Scan(&version)
+	return version, translate(result.Error)
+}
+
+// Update writes all fields of a product to the database. The write only
+// succeeds if the stored version still equals product.Version, in which case
+// the version is incremented; otherwise the product was changed concurrently
+// and ErrVersionMismatch is returned. Like Create, it returns a
+// *ConflictError if the SKU or barcode is taken.
+func (r *ProductRepository) Update(product *models.Product) error {
+	if err := updateProduct(r.db, product); err != nil {
+		return err
+	}
+
+	r.suggestions.upsert(*product)
+	return nil
+}
+
+// updateProduct writes all fields of a product using db, which may be a transaction
+func updateProduct(db *gorm.DB, product *models.Product) error {
+	if err := ensureUnique(db, product); err != nil {
+		return err
+	}
+
+	expected := product.Version
+	product.Version++
+	result := db.Model(product).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("id", "created_at", "deleted_at").
+		Updates(product)
+	if result.Error != nil {
+		product.Version = expected
+		return uniqueViolation(db, product, result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.New("product not found")
+		product.Version = expected
+		return missingOrStale(db, product.ID)
 	}
-	
 	return nil
 }
 
-// Delete removes a product from the database
-func (r *ProductRepository) Delete(id uint) error {
-	result := database.DB.Delete(&models.Product{}, id)
+// UpdateColumns writes only the given columns of a product to the database.
+// The normalized text and GTIN columns are included whenever the name,
+// description or barcode changes. Like Update, the write is conditional on product.Version.
+func (r *ProductRepository) UpdateColumns(product *models.Product, columns []string) error {
+	columns = expandColumns(columns)
+	if err := ensureUnique(r.db, product); err != nil {
+		return err
+	}
+
+	expected := product.Version
+	product.Version++
+	result := r.db.Model(product).
+		Where("version = ?", expected).
+		Select(columns).
+		Updates(product)
+	if result.Error != nil {
+		product.Version = expected
+		return uniqueViolation(r.db, product, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		product.Version = expected
+		return missingOrStale(r.db, product.ID)
+	}
+
+	r.suggestions.upsert(*product)
+	return nil
+}
+
+// expandColumns adds the columns derived from the given ones, and the
+// version, to the columns written by UpdateColumns
+func expandColumns(columns []string) []string {
+	expanded := append([]string(nil), columns...)
+	for _, column := range columns {
+		switch column {
+		case "name":
+			expanded = append(expanded, "name_folded", "name_sort_key")
+		case "description":
+			expanded = append(expanded, "description_folded")
+		case "barcode":
+			expanded = append(expanded, "barcode_format", "gtin")
+		}
+	}
+	return append(expanded, "version")
+}
+
+// missingOrStale explains why a conditional write matched no rows
+func missingOrStale(db *gorm.DB, id uint) error {
+	var count int64
+	if err := db.Model(&models.Product{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return translate(err)
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrVersionMismatch
+}
+
+// Delete removes a product from the database. A non-zero version makes the
+// delete conditional on the stored version, like Update.
+func (r *ProductRepository) Delete(id uint, version uint) error {
+	if err := deleteProduct(r.db, id, version); err != nil {
+		return err
+	}
+
+	r.suggestions.remove(id)
+	return nil
+}
+
+// deleteProduct soft-deletes a product using db, which may be a transaction
+func deleteProduct(db *gorm.DB, id uint, version uint) error {
+	query := db
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(&models.Product{}, id)
 	
 	if result.Error != nil {
-		return result.Error
+		return translate(result.Error)
 	}
 	
 	if result.RowsAffected == 0 {
-		return errors.New("product not found")
+		if version != 0 {
+			return missingOrStale(db, id)
+		}
+		return ErrNotFound
 	}
-	
 	return nil
 } 
\ No newline at end of file
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"product-api/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// snippetWords is the number of words included in description snippets
+const snippetWords = 16
+
+// searchScoreExpr computes the relevance of a hit: the BM25 text relevance
+// multiplied by the in-stock, recency and exact SKU boosts of the ranking rules
+const searchScoreExpr = `-bm25(products_fts)
+	* (1 + CASE WHEN products.quantity > 0 THEN ? ELSE 0 END)
+	* (1 + ? / (1 + MAX(julianday('now') - julianday(products.created_at), 0) / ?))
+	* (1 + CASE WHEN UPPER(products.sku) = UPPER(?) THEN ? ELSE 0 END)`
+
+// Search runs a full-text query against product names, descriptions and SKUs.
+// The query is folded the same way as the indexed text, so matching ignores
+// case, Turkish dotted/dotless I and diacritics, and every term is expanded
+// with its configured synonyms. Results are ordered by boosted relevance
+// unless the query specifies a sort, and soft-deleted products are never
+// returned.
+func (r *ProductRepository) Search(text string, query models.ProductQuery) ([]models.ProductSearchHit, int64, error) {
+	synonyms, err := loadSynonyms(r.db)
+	if err != nil {
+		return nil, 0, err
+	}
+	ranking, err := loadSearchRanking(r.db)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	terms := searchTerms(text)
+	match, highlightTerms := buildMatchQuery(terms, synonyms)
+	if match == "" {
+		return []models.ProductSearchHit{}, 0, nil
+	}
+
+	var total int64
+	if err := searchScope(r.db, match, query).Count(&total).Error; err != nil {
+		return nil, 0, translate(err)
+	}
+
+	hits := []models.ProductSearchHit{}
+	db := searchScope(r.db, match, query).
+		Select("products.*, bm25(products_fts) AS rank, "+searchScoreExpr+" AS score",
+			ranking.InStockBoost,
+			ranking.RecencyBoost, ranking.RecencyHalfLifeDays,
+			strings.TrimSpace(text), ranking.ExactSKUBoost)
+	if len(query.Sort) > 0 {
+		db = applyProductSort(db, query.Sort)
+	} else {
+		db = db.Order("score DESC").Order("products.id")
+	}
+	result := db.Limit(query.Limit).Offset(query.Offset).Scan(&hits)
+	if result.Error != nil {
+		return nil, 0, translate(result.Error)
+	}
+
+	// Highlight against the original text rather than the folded index
+	for i := range hits {
+		hits[i].NameHighlight = highlight(hits[i].Name, highlightTerms)
+		hits[i].DescriptionSnippet = snippet(hits[i].Description, highlightTerms, snippetWords)
+	}
+	return hits, total, nil
+}
+
+// searchScope joins the FTS index to live products and applies the listing filters
+func searchScope(db *gorm.DB, match string, query models.ProductQuery) *gorm.DB {
+	db = db.Table("products_fts").
+		Joins("JOIN products ON products.id = products_fts.rowid").
+		Where("products_fts MATCH ?", match).
+		Where("products.deleted_at IS NULL")
+	return applyProductFilters(db, query)
+}
+
+// buildMatchQuery turns folded search terms into an FTS5 query where every
+// term, or one of its synonyms, must match as a prefix. Terms are quoted so
+// user input cannot inject FTS syntax. It also returns the words to highlight.
+func buildMatchQuery(terms []string, synonyms map[string][]string) (string, []string) {
+	groups := make([]string, len(terms))
+	highlightTerms := append([]string(nil), terms...)
+	for i, term := range terms {
+		alternatives := []string{quoteTerm(term)}
+		for _, synonym := range synonyms[term] {
+			alternatives = append(alternatives, quoteTerm(synonym))
+			highlightTerms = append(highlightTerms, searchTerms(synonym)...)
+		}
+		groups[i] = "(" + strings.Join(alternatives, " OR ") + ")"
+	}
+	return strings.Join(groups, " AND "), highlightTerms
+}
+
+// quoteTerm quotes a word or phrase as an FTS5 prefix query
+func quoteTerm(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+}
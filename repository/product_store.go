@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"product-api/models"
+	"time"
+)
+
+// ProductStore stores products. ProductRepository keeps them in SQLite and
+// MemoryProductStore in memory; both behave the same, which is checked by
+// the storetest package.
+//
+// Products are soft-deleted: deleted products stay in the trash, invisible
+// to every method that does not mention the trash or say otherwise, until
+// they are restored or deleted permanently. Writes set CreatedAt and
+// UpdatedAt, keep the normalized fields of models.Product up to date and
+// increment the version, which makes writes conditional where a version is
+// expected. Errors are the repository errors, e.g. ErrNotFound, or a
+// *ConflictError when a SKU or barcode is already used by a live product.
+type ProductStore interface {
+	// Create adds a new product and fills in its ID, timestamps and version
+	Create(product *models.Product) error
+	// GetAll retrieves all live products
+	GetAll() ([]models.Product, error)
+	// List retrieves a filtered, sorted page of live products and the total
+	// number of products matching the filters
+	List(query models.ProductQuery) ([]models.Product, int64, error)
+	// Count returns the number of live products matching the filters
+	Count(query models.ProductQuery) (int64, error)
+	// CountAll returns the number of products, including trashed ones
+	CountAll() (int64, error)
+	// ListKeyset retrieves up to query.Limit filtered products in
+	// (updated_at, id) order after the position, and whether more follow
+	ListKeyset(query models.ProductQuery, after *models.KeysetPosition) ([]models.Product, bool, error)
+	// ListFacets computes facets over the live products matching the filters
+	ListFacets(query models.ProductQuery, request models.FacetRequest) (models.Facets, error)
+	// Export passes every product matching the filters to fn in sort order
+	Export(query models.ProductQuery, fn func(models.Product) error) error
+
+	// GetByID retrieves a live product by its ID
+	GetByID(id uint) (models.Product, error)
+	// GetBySKU retrieves the live product with the given SKU
+	GetBySKU(sku string) (models.Product, error)
+	// GetByBarcode retrieves the live product with the barcode in any format
+	GetByBarcode(code string) (models.Product, error)
+	// GetVersion retrieves the version and update time of a live product
+	GetVersion(id uint) (models.ProductVersion, error)
+	// TableVersion summarizes all products so listings can be revalidated
+	TableVersion() (models.ProductTableVersion, error)
+
+	// Update writes all fields of a product if its version is current
+	Update(product *models.Product) error
+	// UpdateColumns writes the given columns of a product if its version is current
+	UpdateColumns(product *models.Product, columns []string) error
+	// Delete moves a product to the trash; a non-zero version must be current
+	Delete(id uint, version uint) error
+	// UpsertBySKU creates, replaces or revives the product with product.SKU
+	UpsertBySKU(product *models.Product, version uint) (created bool, err error)
+	// ApplyBatch applies batch operations, all or nothing when atomic
+	ApplyBatch(operations []models.ProductBatchOperation, atomic bool) ([]BatchOutcome, error)
+	// ImportBySKU upserts products by SKU as one unit, or only checks them on a dry run
+	ImportBySKU(products []models.Product, columns []string, dryRun bool, progress func(done int64) error) ([]ImportOutcome, error)
+	// ChangePrices changes the price of every live product matching the filters
+	ChangePrices(query models.ProductQuery, change models.PriceChange, progress func(done int64) error) (models.PriceChangeResult, error)
+	// Reindex recomputes the normalized fields and search indexes
+	Reindex(progress func(done int64) error) error
+
+	// ListTrash retrieves a filtered, sorted page of trashed products
+	ListTrash(query models.ProductQuery) ([]models.Product, int64, error)
+	// GetByIDUnscoped retrieves a product by its ID, live or trashed
+	GetByIDUnscoped(id uint) (models.Product, error)
+	// Restore moves a product out of the trash
+	Restore(id uint) (models.Product, error)
+	// DeletePermanently removes a product, live or trashed; a non-zero version must be current
+	DeletePermanently(id uint, version uint) error
+	// PurgeTrash permanently removes products trashed before the given time
+	PurgeTrash(before time.Time) (int64, error)
+
+	// Search runs a full-text query against live products
+	Search(text string, query models.ProductQuery) ([]models.ProductSearchHit, int64, error)
+	// SearchFacets computes facets over the products matching a search
+	SearchFacets(text string, query models.ProductQuery, request models.FacetRequest) (models.Facets, error)
+	// Suggest returns products whose name or SKU starts with the prefix
+	Suggest(prefix string, limit int) ([]models.ProductSuggestion, error)
+}
+
+// SearchConfigSource provides the synonyms and ranking rules used by search.
+// SearchConfigRepository is one.
+type SearchConfigSource interface {
+	ListSynonyms() ([]models.Synonym, error)
+	GetRanking() (models.SearchRanking, error)
+}
+
+// Both implementations must stay complete
+var (
+	_ ProductStore       = (*ProductRepository)(nil)
+	_ ProductStore       = (*MemoryProductStore)(nil)
+	_ SearchConfigSource = (*SearchConfigRepository)(nil)
+)
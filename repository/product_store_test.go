@@ -0,0 +1,28 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"product-api/database"
+	"product-api/repository"
+	"product-api/repository/storetest"
+	"testing"
+
+	"gorm.io/gorm/logger"
+)
+
+func TestMemoryProductStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.ProductStore {
+		return repository.NewMemoryProductStore(nil)
+	})
+}
+
+func TestProductRepository(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.ProductStore {
+		db, err := database.Open(filepath.Join(t.TempDir(), "products.db"), logger.Silent)
+		if err != nil {
+			t.Fatalf("database.Open = %v", err)
+		}
+		t.Cleanup(func() { database.Close(db) })
+		return repository.NewProductRepository(db)
+	})
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"product-api/models"
+	"product-api/suggest"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// suggestIndex wraps the autocomplete index and loads it from the database
+// the first time it is queried
+type suggestIndex struct {
+	mu     sync.Mutex
+	loaded bool
+	index  *suggest.Index
+}
+
+// load fills the index from all live products in db unless it is already loaded
+func (s *suggestIndex) load(db *gorm.DB) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return nil
+	}
+
+	var entries []suggest.Entry
+	result := db.Model(&models.Product{}).Select("id", "name", "sku").Find(&entries)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	s.index.Rebuild(entries)
+	s.loaded = true
+	return nil
+}
+
+// reset drops the loaded entries so the index is loaded again on next use
+func (s *suggestIndex) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = false
+}
+
+// upsert refreshes the entry of a product once the index is loaded
+func (s *suggestIndex) upsert(product models.Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		s.index.Upsert(suggest.Entry{ID: product.ID, Name: product.Name, SKU: product.SKU})
+	}
+}
+
+// remove drops the entry of a deleted product once the index is loaded
+func (s *suggestIndex) remove(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		s.index.Remove(id)
+	}
+}
+
+// Suggest returns up to limit products whose name or SKU starts with the
+// prefix, tolerating typos in names
+func (r *ProductRepository) Suggest(prefix string, limit int) ([]models.ProductSuggestion, error) {
+	if err := r.suggestions.load(r.db); err != nil {
+		return nil, err
+	}
+
+	return suggestionsOf(r.suggestions.index.Suggest(prefix, limit)), nil
+}
+
+// suggestionsOf converts the results of the autocomplete index
+func suggestionsOf(results []suggest.Result) []models.ProductSuggestion {
+	suggestions := make([]models.ProductSuggestion, len(results))
+	for i, result := range results {
+		suggestions[i] = models.ProductSuggestion{
+			ID:    result.ID,
+			Name:  result.Name,
+			SKU:   result.SKU,
+			Score: result.Score,
+		}
+	}
+	return suggestions
+}
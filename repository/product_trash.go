@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"product-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListTrash retrieves a filtered, sorted page of soft-deleted products along
+// with the total number of trashed products matching the filters. Without a
+// sort, the most recently deleted products come first.
+func (r *ProductRepository) ListTrash(query models.ProductQuery) ([]models.Product, int64, error) {
+	trashed := func() *gorm.DB {
+		return applyProductFilters(r.db.Unscoped().Model(&models.Product{}), query).
+			Where("products.deleted_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := trashed().Count(&total).Error; err != nil {
+		return nil, 0, translate(err)
+	}
+
+	db := trashed()
+	if len(query.Sort) == 0 {
+		db = db.Order("products.deleted_at DESC")
+	}
+	var products []models.Product
+	result := applyProductSort(db, query.Sort).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&products)
+	return products, total, translate(result.Error)
+}
+
+// GetByIDUnscoped retrieves a product by its ID, including soft-deleted products
+func (r *ProductRepository) GetByIDUnscoped(id uint) (models.Product, error) {
+	var product models.Product
+	result := r.db.Unscoped().First(&product, id)
+	if result.Error != nil {
+		return models.Product{}, translate(result.Error)
+	}
+	return product, nil
+}
+
+// Restore moves a soft-deleted product out of the trash and increments its
+// version. It returns ErrNotFound if the product is not in the
+// trash and a *ConflictError if a live product has taken its SKU or barcode
+// in the meantime.
+func (r *ProductRepository) Restore(id uint) (models.Product, error) {
+	var product models.Product
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&product, id)
+	if result.Error != nil {
+		return models.Product{}, translate(result.Error)
+	}
+
+	if err := ensureUnique(r.db, &product); err != nil {
+		return models.Product{}, err
+	}
+
+	now := r.db.NowFunc()
+	result = r.db.Unscoped().Model(&models.Product{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", id, product.Version).
+		UpdateColumns(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    product.Version + 1,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return models.Product{}, uniqueViolation(r.db, &product, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return models.Product{}, ErrNotFound
+	}
+
+	product.DeletedAt.Valid = false
+	product.Version++
+	product.UpdatedAt = &now
+	r.suggestions.upsert(product)
+	return product, nil
+}
+
+// DeletePermanently removes a product from the database, whether it is live
+// or in the trash. A non-zero version makes the delete conditional on the
+// stored version, like Delete.
+func (r *ProductRepository) DeletePermanently(id uint, version uint) error {
+	db := r.db.Unscoped()
+	if version != 0 {
+		db = db.Where("version = ?", version)
+	}
+	result := db.Delete(&models.Product{}, id)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := r.db.Unscoped().Model(&models.Product{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return translate(err)
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+		return ErrVersionMismatch
+	}
+
+	r.suggestions.remove(id)
+	return nil
+}
+
+// PurgeTrash permanently removes products that were soft-deleted before the
+// given time and returns how many were removed
+func (r *ProductRepository) PurgeTrash(before time.Time) (int64, error) {
+	result := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before.UTC()).
+		Delete(&models.Product{})
+	return result.RowsAffected, translate(result.Error)
+}
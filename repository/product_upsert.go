@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// UpsertBySKU creates or replaces the product identified by product.SKU:
+//   - a live product with the SKU has all its fields replaced,
+//   - otherwise the most recently deleted product with the SKU is revived
+//     with the new values,
+//   - otherwise a new product is created.
+//
+// created reports whether no live product existed before. Replacing a
+// product with identical values writes nothing, so repeating a request
+// leaves the version unchanged. A non-zero version makes the replacement
+// conditional on the live product having that version; it fails with
+// ErrNotFound if there is none and ErrVersionMismatch if it
+// has moved on. A *ConflictError is returned if the barcode is taken.
+func (r *ProductRepository) UpsertBySKU(product *models.Product, version uint) (created bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		created, err = upsertBySKU(tx, product, version, nil)
+		return err
+	})
+	if err != nil {
+		return false, translate(err)
+	}
+
+	r.suggestions.upsert(*product)
+	return created, nil
+}
+
+// upsertBySKU implements UpsertBySKU using tx, which must be a transaction.
+// Unless columns is nil, only those columns, named like ChangedColumns, are
+// taken from product when an existing product is replaced or revived.
+func upsertBySKU(tx *gorm.DB, product *models.Product, version uint, columns []string) (bool, error) {
+	var existing models.Product
+	err := tx.Unscoped().
+		Where("sku = ?", product.SKU).
+		Order("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Order("id").
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if version != 0 {
+			return false, ErrNotFound
+		}
+		if err := ensureUnique(tx, product); err != nil {
+			return false, err
+		}
+		if err := tx.Create(product).Error; err != nil {
+			return false, uniqueViolation(tx, product, err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, translate(err)
+	}
+
+	revived := existing.DeletedAt.Valid
+	if version != 0 {
+		if revived {
+			return false, ErrNotFound
+		}
+		if existing.Version != version {
+			return false, ErrVersionMismatch
+		}
+	}
+
+	if columns != nil {
+		product.KeepColumns(existing, columns)
+	}
+
+	// Nothing to write when a live product already has these values
+	update := models.NewProductUpdateDTO(*product)
+	if !revived && len(update.ChangedColumns(existing)) == 0 {
+		*product = existing
+		return false, nil
+	}
+
+	product.ID = existing.ID
+	product.CreatedAt = existing.CreatedAt
+	product.Version = existing.Version + 1
+	if err := ensureUnique(tx, product); err != nil {
+		return false, err
+	}
+
+	// Selecting every column also clears deleted_at of a revived product
+	result := tx.Unscoped().Model(product).
+		Where("version = ?", existing.Version).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(product)
+	if result.Error != nil {
+		return false, uniqueViolation(tx, product, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, ErrVersionMismatch
+	}
+	return revived, nil
+}
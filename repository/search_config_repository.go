@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"product-api/models"
+
+	"gorm.io/gorm"
+)
+
+// searchRankingID is the primary key of the single search ranking row
+const searchRankingID = 1
+
+// SearchConfigRepository handles database operations for search synonyms
+// and ranking rules
+type SearchConfigRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchConfigRepository creates a search configuration repository on db
+func NewSearchConfigRepository(db *gorm.DB) *SearchConfigRepository {
+	return &SearchConfigRepository{db: db}
+}
+
+// ListSynonyms retrieves all synonym entries ordered by term
+func (r *SearchConfigRepository) ListSynonyms() ([]models.Synonym, error) {
+	synonyms := []models.Synonym{}
+	result := r.db.Order("term").Find(&synonyms)
+	return synonyms, translate(result.Error)
+}
+
+// GetSynonym retrieves a synonym entry by its ID
+func (r *SearchConfigRepository) GetSynonym(id uint) (models.Synonym, error) {
+	var synonym models.Synonym
+	result := r.db.First(&synonym, id)
+	if result.Error != nil {
+		return models.Synonym{}, translate(result.Error)
+	}
+	return synonym, nil
+}
+
+// GetSynonymByTerm retrieves the synonym entry for a folded term
+func (r *SearchConfigRepository) GetSynonymByTerm(term string) (models.Synonym, error) {
+	var synonym models.Synonym
+	result := r.db.Where("term = ?", term).First(&synonym)
+	if result.Error != nil {
+		return models.Synonym{}, translate(result.Error)
+	}
+	return synonym, nil
+}
+
+// CreateSynonym adds a new synonym entry
+func (r *SearchConfigRepository) CreateSynonym(synonym *models.Synonym) error {
+	return translate(r.db.Create(synonym).Error)
+}
+
+// UpdateSynonym saves changes to a synonym entry
+func (r *SearchConfigRepository) UpdateSynonym(synonym models.Synonym) error {
+	return translate(r.db.Save(&synonym).Error)
+}
+
+// DeleteSynonym removes a synonym entry
+func (r *SearchConfigRepository) DeleteSynonym(id uint) error {
+	result := r.db.Delete(&models.Synonym{}, id)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetRanking retrieves the search ranking rules, falling back to the defaults
+// when none have been saved yet
+func (r *SearchConfigRepository) GetRanking() (models.SearchRanking, error) {
+	return loadSearchRanking(r.db)
+}
+
+// SaveRanking stores the search ranking rules
+func (r *SearchConfigRepository) SaveRanking(ranking *models.SearchRanking) error {
+	ranking.ID = searchRankingID
+	return translate(r.db.Save(ranking).Error)
+}
+
+// loadSearchRanking reads the ranking rules used by product search
+func loadSearchRanking(db *gorm.DB) (models.SearchRanking, error) {
+	var ranking models.SearchRanking
+	result := db.First(&ranking, searchRankingID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.DefaultSearchRanking(), nil
+	}
+	return ranking, translate(result.Error)
+}
+
+// loadSynonyms reads the synonym dictionary used by product search, keyed by term
+func loadSynonyms(db *gorm.DB) (map[string][]string, error) {
+	var synonyms []models.Synonym
+	if err := db.Find(&synonyms).Error; err != nil {
+		return nil, translate(err)
+	}
+	dictionary := make(map[string][]string, len(synonyms))
+	for _, s := range synonyms {
+		dictionary[s.Term] = s.Synonyms
+	}
+	return dictionary, nil
+}
@@ -0,0 +1,355 @@
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"product-api/models"
+	"product-api/repository"
+	"sync"
+	"testing"
+)
+
+// checkUpsertBySKU checks creating, replacing and reviving products by SKU
+func checkUpsertBySKU(t *testing.T, store repository.ProductStore) {
+	product := newProduct("Su Şişesi", "BOTTLE", 15, 10)
+	created, err := store.UpsertBySKU(&product, 0)
+	if err != nil || !created || product.ID == 0 || product.Version != 1 {
+		t.Fatalf("UpsertBySKU of a new SKU = %v, %v, product %d version %d", created, err, product.ID, product.Version)
+	}
+	first := clone(product)
+
+	// The same values again change nothing
+	tick()
+	same := newProduct("Su Şişesi", "BOTTLE", 15, 10)
+	created, err = store.UpsertBySKU(&same, 0)
+	if err != nil || created || same.ID != first.ID || same.Version != 1 || !sameTime(same.UpdatedAt, first.UpdatedAt) {
+		t.Errorf("repeated UpsertBySKU = %v, %v, product %d version %d updated at %v",
+			created, err, same.ID, same.Version, same.UpdatedAt)
+	}
+
+	changed := newProduct("Cam Su Şişesi", "BOTTLE", 18, 10)
+	created, err = store.UpsertBySKU(&changed, 1)
+	if err != nil || created || changed.ID != first.ID || changed.Version != 2 {
+		t.Errorf("replacing UpsertBySKU = %v, %v, product %d version %d", created, err, changed.ID, changed.Version)
+	}
+	stored := mustGet(t, store, first.ID)
+	if stored.Name != "Cam Su Şişesi" || stored.Price != 18 || !sameTime(stored.CreatedAt, first.CreatedAt) ||
+		!stored.UpdatedAt.After(*first.UpdatedAt) {
+		t.Errorf("GetByID after UpsertBySKU returned %+v", stored)
+	}
+
+	stale := newProduct("Stale", "BOTTLE", 1, 1)
+	_, err = store.UpsertBySKU(&stale, 1)
+	wantErr(t, "UpsertBySKU of an old version", err, repository.ErrVersionMismatch)
+	missing := newProduct("Missing", "MISSING", 1, 1)
+	_, err = store.UpsertBySKU(&missing, 1)
+	wantErr(t, "UpsertBySKU with a version of a missing SKU", err, repository.ErrNotFound)
+
+	// A trashed product is revived rather than duplicated
+	if err := store.Delete(first.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	revived := newProduct("Yeni Şişe", "BOTTLE", 20, 5)
+	created, err = store.UpsertBySKU(&revived, 0)
+	if err != nil || !created || revived.ID != first.ID || revived.Version != 3 || revived.DeletedAt.Valid {
+		t.Errorf("reviving UpsertBySKU = %v, %v, product %d version %d", created, err, revived.ID, revived.Version)
+	}
+	if stored := mustGet(t, store, first.ID); stored.Name != "Yeni Şişe" {
+		t.Errorf("revived product has name %q", stored.Name)
+	}
+
+	// Barcodes stay unique
+	other := newProduct("Other", "OTHER", 1, 1)
+	other.Barcode = ean13
+	other = mustCreate(t, store, other)
+	taken := newProduct("Taken", "BOTTLE", 20, 5)
+	taken.Barcode = ean13
+	_, err = store.UpsertBySKU(&taken, 0)
+	wantConflict(t, "UpsertBySKU with a used barcode", err, "barcode", other.ID)
+}
+
+// batchProduct returns the product data of a batch operation
+func batchProduct(name, sku string, price float64) *models.ProductUpdateDTO {
+	return &models.ProductUpdateDTO{Name: name, SKU: sku, Price: price}
+}
+
+// checkApplyBatchAtomic checks that a failing operation rolls back the batch
+func checkApplyBatchAtomic(t *testing.T, store repository.ProductStore) {
+	existing := mustCreate(t, store, newProduct("Existing", "EXISTING", 10, 1))
+
+	operations := []models.ProductBatchOperation{
+		{Op: models.BatchCreate, Product: batchProduct("New", "NEW", 5)},
+		{Op: models.BatchUpdate, ID: existing.ID, Product: batchProduct("Renamed", "EXISTING", 11)},
+		{Op: models.BatchUpdate, ID: existing.ID + 100, Product: batchProduct("Missing", "MISSING", 1)},
+		{Op: models.BatchDelete, ID: existing.ID},
+	}
+	outcomes, err := store.ApplyBatch(operations, true)
+	if err != nil {
+		t.Fatalf("ApplyBatch = %v", err)
+	}
+	if len(outcomes) != 3 || outcomes[0].Err != nil || outcomes[1].Err != nil {
+		t.Fatalf("ApplyBatch returned %d outcomes, want 3 ending with the failure", len(outcomes))
+	}
+	wantErr(t, "failing batch operation", outcomes[2].Err, repository.ErrNotFound)
+
+	if _, err := store.GetBySKU("NEW"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("rolled back batch created a product: %v", err)
+	}
+	if stored := mustGet(t, store, existing.ID); stored.Name != "Existing" || stored.Version != 1 {
+		t.Errorf("rolled back batch updated the product to %q version %d", stored.Name, stored.Version)
+	}
+
+	operations = []models.ProductBatchOperation{
+		{Op: models.BatchCreate, Product: batchProduct("New", "NEW", 5)},
+		{Op: models.BatchUpdate, ID: existing.ID, Version: 1, Product: batchProduct("Renamed", "EXISTING", 11)},
+		{Op: models.BatchDelete, ID: existing.ID, Version: 2},
+	}
+	outcomes, err = store.ApplyBatch(operations, true)
+	if err != nil || len(outcomes) != 3 {
+		t.Fatalf("ApplyBatch = %d outcomes, %v", len(outcomes), err)
+	}
+	for i, outcome := range outcomes {
+		if outcome.Err != nil {
+			t.Errorf("batch operation %d = %v", i, outcome.Err)
+		}
+	}
+	if outcomes[0].Product.ID == 0 || outcomes[1].Product.Version != 2 {
+		t.Errorf("batch outcomes returned product %d and version %d", outcomes[0].Product.ID, outcomes[1].Product.Version)
+	}
+	if _, err := store.GetBySKU("NEW"); err != nil {
+		t.Errorf("committed batch did not create the product: %v", err)
+	}
+	_, err = store.GetByID(existing.ID)
+	wantErr(t, "GetByID of a product deleted by a batch", err, repository.ErrNotFound)
+}
+
+// checkApplyBatchPerItem checks that operations succeed or fail on their own
+func checkApplyBatchPerItem(t *testing.T, store repository.ProductStore) {
+	existing := mustCreate(t, store, newProduct("Existing", "EXISTING", 10, 1))
+
+	operations := []models.ProductBatchOperation{
+		{Op: models.BatchCreate, Product: batchProduct("First", "FIRST", 5)},
+		{Op: models.BatchCreate, Product: batchProduct("Duplicate", "EXISTING", 5)},
+		{Op: models.BatchUpdate, ID: existing.ID, Version: 7, Product: batchProduct("Stale", "EXISTING", 1)},
+		{Op: models.BatchDelete, ID: existing.ID},
+	}
+	outcomes, err := store.ApplyBatch(operations, false)
+	if err != nil || len(outcomes) != len(operations) {
+		t.Fatalf("ApplyBatch = %d outcomes, %v", len(outcomes), err)
+	}
+	if outcomes[0].Err != nil || outcomes[3].Err != nil {
+		t.Errorf("valid batch operations failed: %v, %v", outcomes[0].Err, outcomes[3].Err)
+	}
+	wantConflict(t, "batch create with a used SKU", outcomes[1].Err, "sku", existing.ID)
+	wantErr(t, "batch update of an old version", outcomes[2].Err, repository.ErrVersionMismatch)
+
+	if _, err := store.GetBySKU("FIRST"); err != nil {
+		t.Errorf("per-item batch did not create the product: %v", err)
+	}
+	if count, _ := store.Count(models.ProductQuery{}); count != 1 {
+		t.Errorf("Count after the batch = %d, want 1", count)
+	}
+}
+
+// checkImportBySKU checks dry runs, rejected rows, rollback on progress errors
+// and that only the imported columns of existing products are replaced
+func checkImportBySKU(t *testing.T, store repository.ProductStore) {
+	existing := newProduct("Existing", "EXISTING", 10, 1)
+	existing.Barcode = ean13
+	existing = mustCreate(t, store, existing)
+
+	products := func() []models.Product {
+		renamed := newProduct("Existing Renamed", "EXISTING", 11, 1)
+		renamed.Barcode = ean13
+		conflicting := newProduct("Conflicting", "CONFLICT", 3, 1)
+		conflicting.Barcode = ean13
+		return []models.Product{newProduct("Imported", "IMPORTED", 1, 1), renamed, conflicting}
+	}
+	wantOutcomes := func(what string, outcomes []repository.ImportOutcome) {
+		if len(outcomes) != 3 {
+			t.Fatalf("%s returned %d outcomes, want 3", what, len(outcomes))
+		}
+		if outcomes[0].Err != nil || !outcomes[0].Created {
+			t.Errorf("%s of a new SKU = created %v, %v", what, outcomes[0].Created, outcomes[0].Err)
+		}
+		if outcomes[1].Err != nil || outcomes[1].Created || outcomes[1].Product.ID != existing.ID {
+			t.Errorf("%s of an existing SKU = created %v, %v, product %d", what, outcomes[1].Created, outcomes[1].Err, outcomes[1].Product.ID)
+		}
+		wantConflict(t, what+" with a used barcode", outcomes[2].Err, "barcode", existing.ID)
+	}
+
+	var progress []int64
+	outcomes, err := store.ImportBySKU(products(), nil, true, func(done int64) error {
+		progress = append(progress, done)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("dry-run ImportBySKU = %v", err)
+	}
+	wantOutcomes("dry-run ImportBySKU", outcomes)
+	if len(progress) != 3 || progress[2] != 3 {
+		t.Errorf("ImportBySKU reported progress %v, want 1, 2, 3", progress)
+	}
+	if count, _ := store.CountAll(); count != 1 {
+		t.Errorf("dry-run ImportBySKU wrote products, CountAll = %d", count)
+	}
+
+	stop := fmt.Errorf("stopped")
+	_, err = store.ImportBySKU(products(), nil, false, func(done int64) error {
+		if done == 2 {
+			return stop
+		}
+		return nil
+	})
+	wantErr(t, "ImportBySKU stopped by progress", err, stop)
+	if count, _ := store.CountAll(); count != 1 {
+		t.Errorf("stopped ImportBySKU wrote products, CountAll = %d", count)
+	}
+
+	outcomes, err = store.ImportBySKU(products(), nil, false, nil)
+	if err != nil {
+		t.Fatalf("ImportBySKU = %v", err)
+	}
+	wantOutcomes("ImportBySKU", outcomes)
+	if stored := mustGet(t, store, existing.ID); stored.Name != "Existing Renamed" || stored.Version != 2 {
+		t.Errorf("ImportBySKU stored %q version %d", stored.Name, stored.Version)
+	}
+	if _, err := store.GetBySKU("IMPORTED"); err != nil {
+		t.Errorf("ImportBySKU did not create the product: %v", err)
+	}
+	if _, err := store.GetBySKU("CONFLICT"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("ImportBySKU created a rejected product: %v", err)
+	}
+
+	partial := newProduct("Existing Again", "EXISTING", 12, 0)
+	outcomes, err = store.ImportBySKU([]models.Product{partial}, []string{"name", "price", "sku"}, false, nil)
+	if err != nil || len(outcomes) != 1 || outcomes[0].Err != nil {
+		t.Fatalf("ImportBySKU of some columns = %v, %+v", err, outcomes)
+	}
+	stored := mustGet(t, store, existing.ID)
+	if stored.Name != "Existing Again" || stored.Price != 12 || stored.Version != 3 {
+		t.Errorf("ImportBySKU of some columns stored %q at %v version %d", stored.Name, stored.Price, stored.Version)
+	}
+	if stored.Quantity != existing.Quantity || stored.Barcode != existing.Barcode || stored.GTIN != existing.GTIN {
+		t.Errorf("ImportBySKU of some columns changed the others: quantity %d, barcode %q, GTIN %q",
+			stored.Quantity, stored.Barcode, stored.GTIN)
+	}
+}
+
+// checkChangePrices checks bulk price changes
+func checkChangePrices(t *testing.T, store repository.ProductStore) {
+	cheap := mustCreate(t, store, newProduct("Cheap", "CHEAP", 5, 1))
+	expensive := mustCreate(t, store, newProduct("Expensive", "EXPENSIVE", 100, 1))
+	trashed := mustCreate(t, store, newProduct("Trashed", "TRASHED", 50, 1))
+	if err := store.Delete(trashed.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	tick()
+	var done int64
+	change := models.PriceChange{Amount: floatPtr(-10)}
+	result, err := store.ChangePrices(models.ProductQuery{}, change, func(n int64) error {
+		done = n
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChangePrices = %v", err)
+	}
+	if result.Updated != 1 || result.Skipped != 1 || result.Unchanged != 0 || done != 2 {
+		t.Errorf("ChangePrices = %+v with progress %d, want 1 updated, 1 skipped, progress 2", result, done)
+	}
+
+	stored := mustGet(t, store, expensive.ID)
+	if stored.Price != 90 || stored.Version != 2 || !stored.UpdatedAt.After(*expensive.UpdatedAt) {
+		t.Errorf("changed product has price %v version %d updated at %v", stored.Price, stored.Version, stored.UpdatedAt)
+	}
+	if stored := mustGet(t, store, cheap.ID); stored.Price != 5 || stored.Version != 1 {
+		t.Errorf("skipped product has price %v version %d", stored.Price, stored.Version)
+	}
+	if stored, _ := store.GetByIDUnscoped(trashed.ID); stored.Price != 50 {
+		t.Errorf("ChangePrices changed a trashed product to %v", stored.Price)
+	}
+
+	result, err = store.ChangePrices(models.ProductQuery{SKU: "CHEAP"}, models.PriceChange{Percent: floatPtr(10)}, nil)
+	if err != nil || result.Updated != 1 {
+		t.Errorf("filtered ChangePrices = %+v, %v", result, err)
+	}
+	if stored := mustGet(t, store, cheap.ID); stored.Price != 5.5 {
+		t.Errorf("percent change set price %v, want 5.5", stored.Price)
+	}
+}
+
+// checkReindex checks that a reindex keeps products findable
+func checkReindex(t *testing.T, store repository.ProductStore) {
+	product := mustCreate(t, store, newProduct("Bilgisayar", "PC-1", 20000, 1))
+	trashed := mustCreate(t, store, newProduct("Eski Bilgisayar", "PC-0", 5000, 0))
+	if err := store.Delete(trashed.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	var done int64
+	if err := store.Reindex(func(n int64) error { done = n; return nil }); err != nil {
+		t.Fatalf("Reindex = %v", err)
+	}
+	if done != 2 {
+		t.Errorf("Reindex reported progress %d, want 2 including trashed products", done)
+	}
+
+	hits, total, err := store.Search("bilgi", models.ProductQuery{Limit: 10})
+	if err != nil || total != 1 || hits[0].ID != product.ID {
+		t.Errorf("Search after Reindex = %d hits, %v", total, err)
+	}
+	suggestions, err := store.Suggest("bilg", 5)
+	if err != nil || len(suggestions) != 1 || suggestions[0].ID != product.ID {
+		t.Errorf("Suggest after Reindex = %v, %v", suggestions, err)
+	}
+}
+
+// checkConcurrency checks that concurrent writes get distinct IDs and none is lost
+func checkConcurrency(t *testing.T, store repository.ProductStore) {
+	const writers = 8
+	const perWriter = 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				product := newProduct("Concurrent", fmt.Sprintf("C-%d-%d", w, i), 1, 1)
+				if err := store.Create(&product); err != nil {
+					errs <- err
+					continue
+				}
+				product.Quantity = 2
+				if err := store.Update(&product); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent write = %v", err)
+	}
+
+	products, err := store.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll = %v", err)
+	}
+	seen := make(map[uint]bool)
+	for _, product := range products {
+		if seen[product.ID] {
+			t.Errorf("ID %d was assigned twice", product.ID)
+		}
+		seen[product.ID] = true
+		if product.Version != 2 {
+			t.Errorf("product %d has version %d, want 2", product.ID, product.Version)
+		}
+	}
+	if len(products) != writers*perWriter {
+		t.Errorf("GetAll returned %d products, want %d", len(products), writers*perWriter)
+	}
+}
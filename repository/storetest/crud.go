@@ -0,0 +1,315 @@
+package storetest
+
+import (
+	"product-api/models"
+	"product-api/repository"
+	"testing"
+	"time"
+)
+
+// checkCreate checks the fields filled in on insert
+func checkCreate(t *testing.T, store repository.ProductStore) {
+	product := newProduct("Çay Bardağı", "CUP-1", 12.5, 3)
+	product.Barcode = upcA
+	product = mustCreate(t, store, product)
+
+	if product.ID == 0 {
+		t.Errorf("Create left ID 0")
+	}
+	if product.Version != 1 {
+		t.Errorf("Create set version %d, want 1", product.Version)
+	}
+	if product.CreatedAt == nil || product.UpdatedAt == nil {
+		t.Fatalf("Create left CreatedAt %v and UpdatedAt %v", product.CreatedAt, product.UpdatedAt)
+	}
+	if time.Since(*product.CreatedAt) > time.Minute || time.Since(*product.CreatedAt) < 0 {
+		t.Errorf("Create set CreatedAt %v, want now", product.CreatedAt)
+	}
+	if product.NameFolded != "cay bardagi" || product.GTIN != "00036000291452" {
+		t.Errorf("Create normalized name to %q and barcode to %q", product.NameFolded, product.GTIN)
+	}
+
+	stored := mustGet(t, store, product.ID)
+	if stored.Name != product.Name || stored.Price != product.Price || stored.Quantity != product.Quantity ||
+		stored.SKU != product.SKU || stored.Barcode != product.Barcode || stored.Version != 1 {
+		t.Errorf("GetByID returned %+v, want %+v", stored, product)
+	}
+	if !sameTime(stored.CreatedAt, product.CreatedAt) || !sameTime(stored.UpdatedAt, product.UpdatedAt) {
+		t.Errorf("GetByID returned timestamps %v, %v, want %v, %v",
+			stored.CreatedAt, stored.UpdatedAt, product.CreatedAt, product.UpdatedAt)
+	}
+	if stored.DeletedAt.Valid {
+		t.Errorf("GetByID returned a deleted product")
+	}
+
+	second := mustCreate(t, store, newProduct("Tabak", "PLATE-1", 5, 0))
+	if second.ID <= product.ID {
+		t.Errorf("second product got ID %d after %d", second.ID, product.ID)
+	}
+}
+
+// checkCreateConflict checks that SKUs and barcodes are unique among live products
+func checkCreateConflict(t *testing.T, store repository.ProductStore) {
+	first := newProduct("First", "DUP", 1, 1)
+	first.Barcode = upcA
+	first = mustCreate(t, store, first)
+
+	duplicateSKU := newProduct("Second", "DUP", 2, 1)
+	err := store.Create(&duplicateSKU)
+	wantConflict(t, "Create with a used SKU", err, "sku", first.ID)
+
+	// The same GTIN written as EAN-13 is a duplicate too
+	duplicateBarcode := newProduct("Third", "OTHER", 3, 1)
+	duplicateBarcode.Barcode = ean13UPC
+	err = store.Create(&duplicateBarcode)
+	wantConflict(t, "Create with a used barcode", err, "barcode", first.ID)
+
+	if total, _ := store.CountAll(); total != 1 {
+		t.Errorf("CountAll = %d after rejected creates, want 1", total)
+	}
+
+	// A trashed product frees its SKU and barcode
+	if err := store.Delete(first.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	reused := newProduct("Reused", "DUP", 4, 1)
+	reused.Barcode = upcA
+	mustCreate(t, store, reused)
+}
+
+// checkNotFound checks the errors for products that do not exist
+func checkNotFound(t *testing.T, store repository.ProductStore) {
+	product := mustCreate(t, store, newProduct("Only", "ONLY", 1, 1))
+	missing := product.ID + 100
+
+	_, err := store.GetByID(missing)
+	wantErr(t, "GetByID of a missing product", err, repository.ErrNotFound)
+	_, err = store.GetByIDUnscoped(missing)
+	wantErr(t, "GetByIDUnscoped of a missing product", err, repository.ErrNotFound)
+	_, err = store.GetVersion(missing)
+	wantErr(t, "GetVersion of a missing product", err, repository.ErrNotFound)
+	_, err = store.GetBySKU("NONE")
+	wantErr(t, "GetBySKU of a missing SKU", err, repository.ErrNotFound)
+	_, err = store.GetByBarcode(ean13)
+	wantErr(t, "GetByBarcode of a missing barcode", err, repository.ErrNotFound)
+	if _, err := store.GetByBarcode("123"); err == nil {
+		t.Errorf("GetByBarcode of an invalid barcode succeeded")
+	}
+
+	ghost := models.Product{ID: missing, Name: "Ghost", Price: 1, Version: 1}
+	wantErr(t, "Update of a missing product", store.Update(&ghost), repository.ErrNotFound)
+	wantErr(t, "Delete of a missing product", store.Delete(missing, 0), repository.ErrNotFound)
+	wantErr(t, "DeletePermanently of a missing product", store.DeletePermanently(missing, 0), repository.ErrNotFound)
+	_, err = store.Restore(product.ID)
+	wantErr(t, "Restore of a live product", err, repository.ErrNotFound)
+}
+
+// checkUpdate checks versions, timestamps and conflicts of full updates
+func checkUpdate(t *testing.T, store repository.ProductStore) {
+	other := mustCreate(t, store, newProduct("Other", "OTHER", 1, 1))
+	product := mustCreate(t, store, newProduct("Kalem", "PEN", 2, 5))
+	created := clone(product)
+
+	tick()
+	product.Name = "Dolma Kalem"
+	product.Price = 3
+	if err := store.Update(&product); err != nil {
+		t.Fatalf("Update = %v", err)
+	}
+	if product.Version != 2 {
+		t.Errorf("Update set version %d, want 2", product.Version)
+	}
+	if product.UpdatedAt == nil || !product.UpdatedAt.After(*created.UpdatedAt) {
+		t.Errorf("Update set UpdatedAt %v, want after %v", product.UpdatedAt, created.UpdatedAt)
+	}
+
+	stored := mustGet(t, store, product.ID)
+	if stored.Name != "Dolma Kalem" || stored.Price != 3 || stored.Version != 2 || stored.NameFolded != "dolma kalem" {
+		t.Errorf("GetByID after Update returned %+v", stored)
+	}
+	if !sameTime(stored.CreatedAt, created.CreatedAt) {
+		t.Errorf("Update changed CreatedAt from %v to %v", created.CreatedAt, stored.CreatedAt)
+	}
+	if !sameTime(stored.UpdatedAt, product.UpdatedAt) {
+		t.Errorf("GetByID returned UpdatedAt %v, Update set %v", stored.UpdatedAt, product.UpdatedAt)
+	}
+
+	// Writing an old version fails and changes nothing
+	stale := clone(created)
+	stale.Name = "Stale"
+	wantErr(t, "Update of an old version", store.Update(&stale), repository.ErrVersionMismatch)
+	if stale.Version != created.Version {
+		t.Errorf("failed Update changed the version to %d", stale.Version)
+	}
+	if stored := mustGet(t, store, product.ID); stored.Name != "Dolma Kalem" {
+		t.Errorf("failed Update wrote name %q", stored.Name)
+	}
+
+	taken := stored
+	taken.SKU = other.SKU
+	wantConflict(t, "Update to a used SKU", store.Update(&taken), "sku", other.ID)
+
+	version, err := store.GetVersion(product.ID)
+	if err != nil || version.Version != 2 || !sameTime(version.UpdatedAt, product.UpdatedAt) {
+		t.Errorf("GetVersion = %+v, %v, want version 2 updated at %v", version, err, product.UpdatedAt)
+	}
+}
+
+// checkUpdateColumns checks that only the given columns are written
+func checkUpdateColumns(t *testing.T, store repository.ProductStore) {
+	product := mustCreate(t, store, newProduct("Defter", "BOOK", 10, 1))
+
+	tick()
+	changed := clone(product)
+	changed.Name = "Çizgili Defter"
+	changed.Price = 99
+	if err := store.UpdateColumns(&changed, []string{"name"}); err != nil {
+		t.Fatalf("UpdateColumns = %v", err)
+	}
+	if changed.Version != 2 {
+		t.Errorf("UpdateColumns set version %d, want 2", changed.Version)
+	}
+
+	stored := mustGet(t, store, product.ID)
+	if stored.Name != "Çizgili Defter" || stored.NameFolded != "cizgili defter" {
+		t.Errorf("UpdateColumns stored name %q folded %q", stored.Name, stored.NameFolded)
+	}
+	if stored.Price != 10 {
+		t.Errorf("UpdateColumns wrote price %v, which was not listed", stored.Price)
+	}
+	if stored.Version != 2 || !stored.UpdatedAt.After(*product.UpdatedAt) {
+		t.Errorf("UpdateColumns stored version %d updated at %v", stored.Version, stored.UpdatedAt)
+	}
+
+	wantErr(t, "UpdateColumns of an old version", store.UpdateColumns(&product, []string{"price"}), repository.ErrVersionMismatch)
+}
+
+// checkSoftDelete checks that deleted products move to the trash
+func checkSoftDelete(t *testing.T, store repository.ProductStore) {
+	keep := mustCreate(t, store, newProduct("Keep", "KEEP", 1, 1))
+	product := mustCreate(t, store, newProduct("Trash", "TRASH", 1, 1))
+
+	wantErr(t, "Delete of an old version", store.Delete(product.ID, product.Version+1), repository.ErrVersionMismatch)
+	tick()
+	if err := store.Delete(product.ID, product.Version); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	_, err := store.GetByID(product.ID)
+	wantErr(t, "GetByID of a deleted product", err, repository.ErrNotFound)
+	_, err = store.GetBySKU("TRASH")
+	wantErr(t, "GetBySKU of a deleted product", err, repository.ErrNotFound)
+	wantErr(t, "Delete of a deleted product", store.Delete(product.ID, 0), repository.ErrNotFound)
+
+	trashed, err := store.GetByIDUnscoped(product.ID)
+	if err != nil {
+		t.Fatalf("GetByIDUnscoped of a deleted product = %v", err)
+	}
+	if !trashed.DeletedAt.Valid || !trashed.DeletedAt.Time.After(*product.UpdatedAt) {
+		t.Errorf("deleted product has DeletedAt %v", trashed.DeletedAt)
+	}
+	if trashed.Version != product.Version || !sameTime(trashed.UpdatedAt, product.UpdatedAt) {
+		t.Errorf("Delete changed version to %d and UpdatedAt to %v", trashed.Version, trashed.UpdatedAt)
+	}
+
+	products, total, err := store.List(models.ProductQuery{Limit: 10})
+	if err != nil || total != 1 || !sameIDs(ids(products), []uint{keep.ID}) {
+		t.Errorf("List after Delete = %v, %d, %v, want only product %d", ids(products), total, err, keep.ID)
+	}
+	if all, _ := store.GetAll(); len(all) != 1 {
+		t.Errorf("GetAll after Delete returned %d products, want 1", len(all))
+	}
+	if count, _ := store.Count(models.ProductQuery{}); count != 1 {
+		t.Errorf("Count after Delete = %d, want 1", count)
+	}
+	if count, _ := store.CountAll(); count != 2 {
+		t.Errorf("CountAll after Delete = %d, want 2", count)
+	}
+}
+
+// checkRestore checks moving products out of the trash
+func checkRestore(t *testing.T, store repository.ProductStore) {
+	product := mustCreate(t, store, newProduct("Lamba", "LAMP", 20, 1))
+	if err := store.Delete(product.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	tick()
+	restored, err := store.Restore(product.ID)
+	if err != nil {
+		t.Fatalf("Restore = %v", err)
+	}
+	if restored.Version != product.Version+1 || restored.DeletedAt.Valid || !restored.UpdatedAt.After(*product.UpdatedAt) {
+		t.Errorf("Restore returned version %d, deleted %v, updated at %v",
+			restored.Version, restored.DeletedAt.Valid, restored.UpdatedAt)
+	}
+	if stored := mustGet(t, store, product.ID); stored.Version != restored.Version {
+		t.Errorf("GetByID after Restore has version %d, want %d", stored.Version, restored.Version)
+	}
+
+	// A product whose SKU was taken while it was in the trash stays there
+	if err := store.Delete(product.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	taken := mustCreate(t, store, newProduct("New Lamba", "LAMP", 25, 1))
+	_, err = store.Restore(product.ID)
+	wantConflict(t, "Restore with a taken SKU", err, "sku", taken.ID)
+	if trashed, _ := store.GetByIDUnscoped(product.ID); !trashed.DeletedAt.Valid {
+		t.Errorf("failed Restore moved the product out of the trash")
+	}
+}
+
+// checkDeletePermanently checks removing live and trashed products
+func checkDeletePermanently(t *testing.T, store repository.ProductStore) {
+	live := mustCreate(t, store, newProduct("Live", "LIVE", 1, 1))
+	trashed := mustCreate(t, store, newProduct("Trashed", "TRASHED", 1, 1))
+	if err := store.Delete(trashed.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	wantErr(t, "DeletePermanently of an old version", store.DeletePermanently(live.ID, live.Version+1), repository.ErrVersionMismatch)
+	for _, id := range []uint{live.ID, trashed.ID} {
+		if err := store.DeletePermanently(id, 0); err != nil {
+			t.Errorf("DeletePermanently(%d) = %v", id, err)
+		}
+		_, err := store.GetByIDUnscoped(id)
+		wantErr(t, "GetByIDUnscoped after DeletePermanently", err, repository.ErrNotFound)
+	}
+	if count, _ := store.CountAll(); count != 0 {
+		t.Errorf("CountAll after DeletePermanently = %d, want 0", count)
+	}
+
+	// IDs are not reused
+	next := mustCreate(t, store, newProduct("Next", "NEXT", 1, 1))
+	if next.ID <= trashed.ID {
+		t.Errorf("new product got ID %d, which is not after %d", next.ID, trashed.ID)
+	}
+}
+
+// checkPurgeTrash checks that only products trashed before the cutoff are removed
+func checkPurgeTrash(t *testing.T, store repository.ProductStore) {
+	live := mustCreate(t, store, newProduct("Live", "LIVE", 1, 1))
+	old := mustCreate(t, store, newProduct("Old", "OLD", 1, 1))
+	if err := store.Delete(old.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	tick()
+	cutoff := time.Now()
+	tick()
+	recent := mustCreate(t, store, newProduct("Recent", "RECENT", 1, 1))
+	if err := store.Delete(recent.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	purged, err := store.PurgeTrash(cutoff)
+	if err != nil || purged != 1 {
+		t.Errorf("PurgeTrash = %d, %v, want 1", purged, err)
+	}
+	_, err = store.GetByIDUnscoped(old.ID)
+	wantErr(t, "GetByIDUnscoped of a purged product", err, repository.ErrNotFound)
+	for _, id := range []uint{live.ID, recent.ID} {
+		if _, err := store.GetByIDUnscoped(id); err != nil {
+			t.Errorf("PurgeTrash removed product %d: %v", id, err)
+		}
+	}
+}
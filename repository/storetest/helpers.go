@@ -0,0 +1,118 @@
+package storetest
+
+import (
+	"errors"
+	"product-api/models"
+	"product-api/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Barcodes used by the checks. upcA and ean13UPC are two forms of the same GTIN.
+const (
+	ean13    = "4006381333931"
+	upcA     = "036000291452"
+	ean13UPC = "0036000291452"
+)
+
+// newProduct returns an unsaved product
+func newProduct(name, sku string, price float64, quantity int) models.Product {
+	return models.Product{Name: name, SKU: sku, Price: price, Quantity: quantity}
+}
+
+// mustCreate creates a product and stops the check if that fails
+func mustCreate(t *testing.T, store repository.ProductStore, product models.Product) models.Product {
+	t.Helper()
+	if err := store.Create(&product); err != nil {
+		t.Fatalf("Create(%q) = %v", product.SKU, err)
+	}
+	return product
+}
+
+// clone copies a product together with its timestamps. Stores may write the
+// timestamps of the product they are given through its pointers, so a plain
+// copy would change along with the original.
+func clone(product models.Product) models.Product {
+	if product.CreatedAt != nil {
+		product.CreatedAt = timePtr(*product.CreatedAt)
+	}
+	if product.UpdatedAt != nil {
+		product.UpdatedAt = timePtr(*product.UpdatedAt)
+	}
+	return product
+}
+
+// mustGet retrieves a live product and stops the check if that fails
+func mustGet(t *testing.T, store repository.ProductStore, id uint) models.Product {
+	t.Helper()
+	product, err := store.GetByID(id)
+	if err != nil {
+		t.Fatalf("GetByID(%d) = %v", id, err)
+	}
+	return product
+}
+
+// wantErr reports a failure unless err matches target
+func wantErr(t *testing.T, what string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s = %v, want %v", what, err, target)
+	}
+}
+
+// wantConflict reports a failure unless err is a *ConflictError for the
+// field and product
+func wantConflict(t *testing.T, what string, err error, field string, conflictingID uint) {
+	t.Helper()
+	var conflict *repository.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Errorf("%s = %v, want a *ConflictError", what, err)
+		return
+	}
+	if conflict.Field != field || conflict.ConflictingID != conflictingID {
+		t.Errorf("%s conflicts on %s with product %d, want %s with product %d",
+			what, conflict.Field, conflict.ConflictingID, field, conflictingID)
+	}
+	if !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("%s = %v, does not match ErrConflict", what, err)
+	}
+}
+
+// ids returns the IDs of products in order
+func ids(products []models.Product) []uint {
+	result := make([]uint, len(products))
+	for i, product := range products {
+		result[i] = product.ID
+	}
+	return result
+}
+
+// sameIDs reports whether two ID lists are equal
+func sameIDs(a, b []uint) bool {
+	return slices.Equal(a, b)
+}
+
+// sameTime reports whether two optional times are both missing or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// tick waits long enough for the clock to move on, so timestamps of
+// consecutive writes differ
+func tick() {
+	time.Sleep(2 * time.Millisecond)
+}
+
+// timePtr returns a pointer to t
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// floatPtr returns a pointer to f
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,410 @@
+package storetest
+
+import (
+	"errors"
+	"product-api/models"
+	"product-api/repository"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createCatalog creates a small catalog for the listing checks and returns
+// the products in creation order
+func createCatalog(t *testing.T, store repository.ProductStore) []models.Product {
+	t.Helper()
+	catalog := []models.Product{
+		newProduct("Çelik Tencere", "POT-1", 450, 4),
+		newProduct("Cam Bardak", "GLASS-1", 35, 0),
+		newProduct("Ahşap Kaşık", "SPOON-1", 20, 12),
+		newProduct("Döküm Tava", "PAN-1", 900, 2),
+		newProduct("Cezve", "COFFEE-1", 150, 0),
+	}
+	catalog[1].Barcode = ean13
+	catalog[1].Description = "Isıya dayanıklı çay ve su bardağı"
+	for i := range catalog {
+		catalog[i] = mustCreate(t, store, catalog[i])
+		tick()
+	}
+	return catalog
+}
+
+// checkList checks filters, sorting, paging and totals
+func checkList(t *testing.T, store repository.ProductStore) {
+	c := createCatalog(t, store)
+
+	cases := []struct {
+		name  string
+		query models.ProductQuery
+		want  []uint
+		total int64
+	}{
+		{"all", models.ProductQuery{Limit: 10}, ids(c), 5},
+		{"page", models.ProductQuery{Limit: 2, Offset: 2}, []uint{c[2].ID, c[3].ID}, 5},
+		{"past the end", models.ProductQuery{Limit: 2, Offset: 10}, []uint{}, 5},
+		{"folded name", models.ProductQuery{Limit: 10, Name: "CELİK"}, []uint{c[0].ID}, 1},
+		{"name part", models.ProductQuery{Limit: 10, Name: "ka"}, []uint{c[2].ID}, 1},
+		{"name with %", models.ProductQuery{Limit: 10, Name: "%"}, []uint{}, 0},
+		{"name with _", models.ProductQuery{Limit: 10, Name: "k_"}, []uint{}, 0},
+		{"sku", models.ProductQuery{Limit: 10, SKU: "PAN-1"}, []uint{c[3].ID}, 1},
+		{"barcode", models.ProductQuery{Limit: 10, Barcode: "0" + ean13}, []uint{c[1].ID}, 1},
+		{"price range", models.ProductQuery{Limit: 10, MinPrice: floatPtr(35), MaxPrice: floatPtr(450)}, []uint{c[0].ID, c[1].ID, c[4].ID}, 3},
+		{"in stock", models.ProductQuery{Limit: 10, MinQuantity: intPtr(1)}, []uint{c[0].ID, c[2].ID, c[3].ID}, 3},
+		{"price desc", models.ProductQuery{Limit: 10, Sort: []models.SortField{{Column: "products.price", Desc: true}}},
+			[]uint{c[3].ID, c[0].ID, c[4].ID, c[1].ID, c[2].ID}, 5},
+		{"name", models.ProductQuery{Limit: 10, Sort: []models.SortField{{Column: "products.name_sort_key"}}},
+			[]uint{c[2].ID, c[1].ID, c[4].ID, c[0].ID, c[3].ID}, 5},
+		{"quantity then id", models.ProductQuery{Limit: 10, Sort: []models.SortField{{Column: "products.quantity"}}},
+			[]uint{c[1].ID, c[4].ID, c[3].ID, c[0].ID, c[2].ID}, 5},
+		{"newest", models.ProductQuery{Limit: 2, Sort: []models.SortField{{Column: "products.created_at", Desc: true}}},
+			[]uint{c[4].ID, c[3].ID}, 5},
+	}
+	for _, tc := range cases {
+		products, total, err := store.List(tc.query)
+		if err != nil {
+			t.Errorf("List %s = %v", tc.name, err)
+			continue
+		}
+		if !sameIDs(ids(products), tc.want) || total != tc.total {
+			t.Errorf("List %s = %v total %d, want %v total %d", tc.name, ids(products), total, tc.want, tc.total)
+		}
+		if count, err := store.Count(tc.query); err != nil || count != tc.total {
+			t.Errorf("Count %s = %d, %v, want %d", tc.name, count, err, tc.total)
+		}
+	}
+}
+
+// checkListKeyset checks that keyset pages cover every product once in
+// update order
+func checkListKeyset(t *testing.T, store repository.ProductStore) {
+	c := createCatalog(t, store)
+
+	// Updating a product moves it to the end
+	moved := c[1]
+	moved.Price = 40
+	if err := store.Update(&moved); err != nil {
+		t.Fatalf("Update = %v", err)
+	}
+	want := []uint{c[0].ID, c[2].ID, c[3].ID, c[4].ID, c[1].ID}
+
+	var got []uint
+	var after *models.KeysetPosition
+	for pages := 0; pages < 10; pages++ {
+		products, hasMore, err := store.ListKeyset(models.ProductQuery{Limit: 2}, after)
+		if err != nil {
+			t.Fatalf("ListKeyset = %v", err)
+		}
+		got = append(got, ids(products)...)
+		if !hasMore {
+			break
+		}
+		last := products[len(products)-1]
+		after = &models.KeysetPosition{UpdatedAt: *last.UpdatedAt, ID: last.ID}
+	}
+	if !sameIDs(got, want) {
+		t.Errorf("ListKeyset pages = %v, want %v", got, want)
+	}
+
+	products, hasMore, err := store.ListKeyset(models.ProductQuery{Limit: 10, MaxPrice: floatPtr(100)}, nil)
+	if err != nil || hasMore || !sameIDs(ids(products), []uint{c[2].ID, c[1].ID}) {
+		t.Errorf("filtered ListKeyset = %v, %v, %v, want %v", ids(products), hasMore, err, []uint{c[2].ID, c[1].ID})
+	}
+}
+
+// checkListTrash checks listing trashed products, most recently deleted first
+func checkListTrash(t *testing.T, store repository.ProductStore) {
+	c := createCatalog(t, store)
+	for _, product := range []models.Product{c[3], c[0], c[4]} {
+		if err := store.Delete(product.ID, 0); err != nil {
+			t.Fatalf("Delete = %v", err)
+		}
+		tick()
+	}
+
+	products, total, err := store.ListTrash(models.ProductQuery{Limit: 10})
+	if want := []uint{c[4].ID, c[0].ID, c[3].ID}; err != nil || total != 3 || !sameIDs(ids(products), want) {
+		t.Errorf("ListTrash = %v total %d, %v, want %v", ids(products), total, err, want)
+	}
+	for _, product := range products {
+		if !product.DeletedAt.Valid {
+			t.Errorf("ListTrash returned live product %d", product.ID)
+		}
+	}
+
+	query := models.ProductQuery{Limit: 1, MinPrice: floatPtr(200), Sort: []models.SortField{{Column: "products.price"}}}
+	products, total, err = store.ListTrash(query)
+	if err != nil || total != 2 || !sameIDs(ids(products), []uint{c[0].ID}) {
+		t.Errorf("filtered ListTrash = %v total %d, %v, want %v total 2", ids(products), total, err, []uint{c[0].ID})
+	}
+}
+
+// checkLookups checks finding products by SKU and by barcode in any format
+func checkLookups(t *testing.T, store repository.ProductStore) {
+	product := newProduct("Mısır Gevreği", "CEREAL", 60, 10)
+	product.Barcode = upcA
+	product = mustCreate(t, store, product)
+
+	if found, err := store.GetBySKU("CEREAL"); err != nil || found.ID != product.ID {
+		t.Errorf("GetBySKU = %d, %v, want %d", found.ID, err, product.ID)
+	}
+	if _, err := store.GetBySKU("cereal"); err == nil {
+		t.Errorf("GetBySKU matched a SKU in another case")
+	}
+	for _, code := range []string{upcA, ean13UPC, "0" + ean13UPC} {
+		if found, err := store.GetByBarcode(code); err != nil || found.ID != product.ID {
+			t.Errorf("GetByBarcode(%s) = %d, %v, want %d", code, found.ID, err, product.ID)
+		}
+	}
+}
+
+// checkTableVersion checks that the table version changes with every write,
+// including permanent deletes that leave no timestamp behind
+func checkTableVersion(t *testing.T, store repository.ProductStore) {
+	empty, err := store.TableVersion()
+	if err != nil {
+		t.Fatalf("TableVersion = %v", err)
+	}
+
+	seen := map[models.ProductTableVersion]string{empty: "empty"}
+	record := func(step string) {
+		version, err := store.TableVersion()
+		if err != nil {
+			t.Fatalf("TableVersion after %s = %v", step, err)
+		}
+		if previous, ok := seen[version]; ok {
+			t.Errorf("TableVersion after %s equals the one after %s: %+v", step, previous, version)
+		}
+		seen[version] = step
+	}
+
+	product := mustCreate(t, store, newProduct("Saat", "CLOCK", 300, 1))
+	record("Create")
+	product.Price = 320
+	if err := store.Update(&product); err != nil {
+		t.Fatalf("Update = %v", err)
+	}
+	record("Update")
+	if err := store.Delete(product.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	record("Delete")
+	if _, err := store.Restore(product.ID); err != nil {
+		t.Fatalf("Restore = %v", err)
+	}
+	record("Restore")
+	if err := store.DeletePermanently(product.ID, 0); err != nil {
+		t.Fatalf("DeletePermanently = %v", err)
+	}
+	record("DeletePermanently")
+
+	other := mustCreate(t, store, newProduct("Takvim", "CALENDAR", 50, 1))
+	record("second Create")
+	if err := store.Delete(other.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	record("second Delete")
+	tick()
+	if purged, err := store.PurgeTrash(time.Now()); err != nil || purged != 1 {
+		t.Fatalf("PurgeTrash = %d, %v, want 1", purged, err)
+	}
+	record("PurgeTrash")
+}
+
+// checkFacets checks price and stock facets over listings and searches
+func checkFacets(t *testing.T, store repository.ProductStore) {
+	createCatalog(t, store)
+	request := models.FacetRequest{
+		Names:        []string{models.FacetPrice, models.FacetStock},
+		PriceBuckets: []float64{50, 500},
+	}
+
+	facets, err := store.ListFacets(models.ProductQuery{}, request)
+	if err != nil {
+		t.Fatalf("ListFacets = %v", err)
+	}
+	wantBuckets(t, "ListFacets price", facets[models.FacetPrice], map[string]int64{"*-50": 2, "50-500": 2, "500-*": 1})
+	wantBuckets(t, "ListFacets stock", facets[models.FacetStock], map[string]int64{"in_stock": 3, "out_of_stock": 2})
+
+	facets, err = store.ListFacets(models.ProductQuery{MinQuantity: intPtr(1)}, request)
+	if err != nil {
+		t.Fatalf("filtered ListFacets = %v", err)
+	}
+	wantBuckets(t, "filtered ListFacets stock", facets[models.FacetStock], map[string]int64{"in_stock": 3, "out_of_stock": 0})
+
+	facets, err = store.SearchFacets("bardak", models.ProductQuery{}, request)
+	if err != nil {
+		t.Fatalf("SearchFacets = %v", err)
+	}
+	wantBuckets(t, "SearchFacets price", facets[models.FacetPrice], map[string]int64{"*-50": 1, "50-500": 0, "500-*": 0})
+
+	if _, err := store.ListFacets(models.ProductQuery{}, models.FacetRequest{Names: []string{"color"}}); err == nil {
+		t.Errorf("ListFacets of an unknown facet succeeded")
+	}
+}
+
+// wantBuckets reports a failure unless the buckets have the given counts in order
+func wantBuckets(t *testing.T, what string, buckets []models.FacetBucket, want map[string]int64) {
+	t.Helper()
+	got := make(map[string]int64, len(buckets))
+	for _, bucket := range buckets {
+		got[bucket.Key] = bucket.Count
+	}
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for key, count := range want {
+		if got[key] != count {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+	}
+}
+
+// checkExport checks that exports follow the filters and sort and ignore paging
+func checkExport(t *testing.T, store repository.ProductStore) {
+	c := createCatalog(t, store)
+	if err := store.Delete(c[0].ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	var got []uint
+	query := models.ProductQuery{Limit: 1, Offset: 1, MinPrice: floatPtr(30), Sort: []models.SortField{{Column: "products.price", Desc: true}}}
+	err := store.Export(query, func(product models.Product) error {
+		got = append(got, product.ID)
+		return nil
+	})
+	if want := []uint{c[3].ID, c[4].ID, c[1].ID}; err != nil || !sameIDs(got, want) {
+		t.Errorf("Export = %v, %v, want %v", got, err, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = store.Export(models.ProductQuery{}, func(models.Product) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Export with a failing callback = %v after %d calls, want the callback error after 1", err, calls)
+	}
+}
+
+// checkSearch checks full-text matching, filters, ordering and highlighting
+func checkSearch(t *testing.T, store repository.ProductStore) {
+	c := createCatalog(t, store)
+	trashed := mustCreate(t, store, newProduct("Eski Bardak", "OLD-GLASS", 10, 1))
+	if err := store.Delete(trashed.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		text  string
+		query models.ProductQuery
+		want  []uint
+	}{
+		{"prefix", "tenc", models.ProductQuery{}, []uint{c[0].ID}},
+		{"folded", "ÇELİK", models.ProductQuery{}, []uint{c[0].ID}},
+		{"description", "dayanikli", models.ProductQuery{}, []uint{c[1].ID}},
+		{"sku", "spoon", models.ProductQuery{}, []uint{c[2].ID}},
+		{"all terms", "cam bardak", models.ProductQuery{}, []uint{c[1].ID}},
+		{"missing term", "cam tava", models.ProductQuery{}, []uint{}},
+		{"no terms", " - ", models.ProductQuery{}, []uint{}},
+		{"filtered", "c", models.ProductQuery{MinPrice: floatPtr(100)}, []uint{c[0].ID, c[4].ID}},
+	}
+	for _, tc := range cases {
+		tc.query.Limit = 10
+		hits, total, err := store.Search(tc.text, tc.query)
+		if err != nil {
+			t.Errorf("Search %s = %v", tc.name, err)
+			continue
+		}
+		got := make([]uint, len(hits))
+		for i, hit := range hits {
+			got[i] = hit.ID
+		}
+		if !sameIDs(sortedIDs(got), sortedIDs(tc.want)) || total != int64(len(tc.want)) {
+			t.Errorf("Search %s (%q) = %v total %d, want %v", tc.name, tc.text, got, total, tc.want)
+		}
+	}
+
+	// An exact SKU match comes first
+	hits, _, err := store.Search("COFFEE-1", models.ProductQuery{Limit: 10})
+	if err != nil || len(hits) == 0 || hits[0].ID != c[4].ID {
+		t.Errorf("Search for a SKU did not rank the product first: %v", err)
+	}
+
+	// Search sorted by price, paged
+	hits, total, err := store.Search("c", models.ProductQuery{Limit: 2, Offset: 1, Sort: []models.SortField{{Column: "products.price"}}})
+	if err != nil || total != 3 || len(hits) != 2 || hits[0].ID != c[4].ID || hits[1].ID != c[0].ID {
+		t.Errorf("sorted Search = %d hits of %d, %v", len(hits), total, err)
+	}
+
+	hits, _, err = store.Search("bardak", models.ProductQuery{Limit: 10})
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("Search bardak = %d hits, %v", len(hits), err)
+	}
+	if hits[0].NameHighlight != "Cam <mark>Bardak</mark>" {
+		t.Errorf("NameHighlight = %q", hits[0].NameHighlight)
+	}
+	if hits[0].Score <= 0 {
+		t.Errorf("Score = %v, want positive", hits[0].Score)
+	}
+
+	hits, _, err = store.Search("dayan", models.ProductQuery{Limit: 10})
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("Search dayan = %d hits, %v", len(hits), err)
+	}
+	if !strings.Contains(hits[0].DescriptionSnippet, "<mark>dayanıklı</mark>") {
+		t.Errorf("DescriptionSnippet = %q", hits[0].DescriptionSnippet)
+	}
+}
+
+// checkSuggest checks that autocomplete follows writes
+func checkSuggest(t *testing.T, store repository.ProductStore) {
+	product := mustCreate(t, store, newProduct("Kahve Makinesi", "KM-1", 1500, 1))
+	mustCreate(t, store, newProduct("Çaydanlık", "CD-1", 400, 1))
+
+	wantSuggestions := func(step, prefix string, want []uint) {
+		suggestions, err := store.Suggest(prefix, 5)
+		if err != nil {
+			t.Fatalf("Suggest after %s = %v", step, err)
+		}
+		got := make([]uint, len(suggestions))
+		for i, suggestion := range suggestions {
+			got[i] = suggestion.ID
+		}
+		if !sameIDs(got, want) {
+			t.Errorf("Suggest(%q) after %s = %v, want %v", prefix, step, got, want)
+		}
+	}
+
+	wantSuggestions("Create", "kahv", []uint{product.ID})
+	product.Name = "Espresso Makinesi"
+	if err := store.Update(&product); err != nil {
+		t.Fatalf("Update = %v", err)
+	}
+	wantSuggestions("Update", "kahv", []uint{})
+	wantSuggestions("Update", "espr", []uint{product.ID})
+	if err := store.Delete(product.ID, 0); err != nil {
+		t.Fatalf("Delete = %v", err)
+	}
+	wantSuggestions("Delete", "espr", []uint{})
+	if _, err := store.Restore(product.ID); err != nil {
+		t.Fatalf("Restore = %v", err)
+	}
+	wantSuggestions("Restore", "espr", []uint{product.ID})
+}
+
+// sortedIDs returns the IDs in ascending order
+func sortedIDs(list []uint) []uint {
+	sorted := slices.Clone(list)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// intPtr returns a pointer to i
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,62 @@
+// Package storetest checks that a repository.ProductStore behaves like the
+// stores of this module: soft delete, versions, timestamps, uniqueness,
+// upserts, batches, imports, listing and search. Every implementation must
+// pass it, so the in-memory store can stand in for SQLite.
+//
+// The checks live in a regular package rather than a test file so that the
+// tests of every store can run them:
+//
+//	func TestMemoryProductStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) repository.ProductStore {
+//			return repository.NewMemoryProductStore(nil)
+//		})
+//	}
+package storetest
+
+import (
+	"product-api/repository"
+	"testing"
+)
+
+// checks are run in order, each against a new store
+var checks = []struct {
+	name string
+	run  func(t *testing.T, store repository.ProductStore)
+}{
+	{"Create", checkCreate},
+	{"CreateConflict", checkCreateConflict},
+	{"NotFound", checkNotFound},
+	{"Update", checkUpdate},
+	{"UpdateColumns", checkUpdateColumns},
+	{"SoftDelete", checkSoftDelete},
+	{"Restore", checkRestore},
+	{"DeletePermanently", checkDeletePermanently},
+	{"PurgeTrash", checkPurgeTrash},
+	{"List", checkList},
+	{"ListKeyset", checkListKeyset},
+	{"ListTrash", checkListTrash},
+	{"Lookups", checkLookups},
+	{"TableVersion", checkTableVersion},
+	{"UpsertBySKU", checkUpsertBySKU},
+	{"ApplyBatchAtomic", checkApplyBatchAtomic},
+	{"ApplyBatchPerItem", checkApplyBatchPerItem},
+	{"ImportBySKU", checkImportBySKU},
+	{"ChangePrices", checkChangePrices},
+	{"Facets", checkFacets},
+	{"Export", checkExport},
+	{"Search", checkSearch},
+	{"Suggest", checkSuggest},
+	{"Reindex", checkReindex},
+	{"Concurrency", checkConcurrency},
+}
+
+// Run runs every check as a subtest of t, named after the check so that
+// one can be selected with -run, each against a new, empty store from
+// newStore. newStore may register cleanups on the subtest it is given.
+func Run(t *testing.T, newStore func(t *testing.T) repository.ProductStore) {
+	for _, check := range checks {
+		t.Run(check.name, func(t *testing.T) {
+			check.run(t, newStore(t))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package retention periodically purges products that have been in the
+// trash for longer than the retention period.
+package retention
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Defaults used when the job is not configured. Purging deletes products for
+// good, so it stays off until a retention period is set.
+const (
+	DefaultRetentionDays = 0
+	DefaultInterval      = time.Hour
+)
+
+// Purger permanently removes products trashed before the given time
+type Purger interface {
+	PurgeTrash(before time.Time) (int64, error)
+}
+
+// Config holds the retention settings
+type Config struct {
+	Retention time.Duration // How long products stay in the trash, 0 disables purging
+	Interval  time.Duration // How often the trash is checked
+}
+
+// Start purges the trash once and then on every interval in the background.
+// The returned function stops the job and waits for a running purge to finish.
+func Start(purger Purger, config Config) (stop func()) {
+	if config.Retention <= 0 {
+		log.Println("Trash retention disabled, trashed products are kept until purged manually")
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			purge(purger, config.Retention)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// purge removes the products trashed longer than the retention period ago
+func purge(purger Purger, retention time.Duration) {
+	count, err := purger.PurgeTrash(time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("Failed to purge trash: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Permanently deleted %d products from the trash", count)
+	}
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"log"
+	"product-api/models"
+	"product-api/repository"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// authorizeAdmin checks the admin bearer token against adminToken, which
+// disables the admin endpoints when empty. It returns the status code,
+// error code and message to reject the request with, or 0 if the request is allowed.
+func authorizeAdmin(c *fiber.Ctx, adminToken string) (int, string, string) {
+	if adminToken == "" {
+		return fiber.StatusForbidden, models.ProblemForbidden, "Admin endpoints are disabled"
+	}
+
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		return fiber.StatusUnauthorized, models.ProblemUnauthorized, "Invalid admin token"
+	}
+	return 0, "", ""
+}
+
+// requireAdmin returns a middleware that rejects requests that do not carry
+// the admin bearer token
+func requireAdmin(adminToken string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if status, code, msg := authorizeAdmin(c, adminToken); status != 0 {
+			return problem(c, status, code, msg)
+		}
+		return c.Next()
+	}
+}
+
+// SetupAdminRoutes configures the admin routes, all of which require the admin token
+func SetupAdminRoutes(app *fiber.App, searchConfig *repository.SearchConfigRepository, config Config) {
+	h := &searchConfigHandlers{searchConfig: searchConfig}
+
+	if config.AdminToken == "" {
+		log.Println("ADMIN_TOKEN not set, admin endpoints are disabled")
+	}
+	admin := app.Group("/api/v1/admin", requireAdmin(config.AdminToken))
+
+	// Search configuration routes
+	search := admin.Group("/search")
+	search.Get("/synonyms", h.getSynonymsHandler)          // GET /api/v1/admin/search/synonyms
+	search.Post("/synonyms", h.addSynonymHandler)          // POST /api/v1/admin/search/synonyms
+	search.Put("/synonyms/:id", h.updateSynonymHandler)    // PUT /api/v1/admin/search/synonyms/:id
+	search.Delete("/synonyms/:id", h.deleteSynonymHandler) // DELETE /api/v1/admin/search/synonyms/:id
+	search.Get("/ranking", h.getSearchRankingHandler)      // GET /api/v1/admin/search/ranking
+	search.Put("/ranking", h.updateSearchRankingHandler)   // PUT /api/v1/admin/search/ranking
+}
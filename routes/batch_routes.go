@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"product-api/models"
+	"product-api/repository"
+	"product-api/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// batchProductsHandler handles POST requests to apply many product changes at once
+// @Summary Create, update and delete products in bulk
+// @Description Apply a list of create, update and delete operations in order. Each operation
+// @Description is validated like its single-product endpoint; version works like If-Match.
+// @Description In atomic mode (default) all operations run in one transaction. Invalid operations
+// @Description reject the batch with validation_failed; if an operation fails, nothing is applied
+// @Description and the response is a batch_failed problem whose results hold the failing operation.
+// @Description In per_item mode every operation is applied on its own and the response is
+// @Description always 200 with a result per operation.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param batch body models.ProductBatchRequest true "Batch of operations"
+// @Success 200 {object} models.ProductBatchResponse
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /products/batch [post]
+func (h *productHandlers) batchProductsHandler(c *fiber.Ctx) error {
+	var request models.ProductBatchRequest
+	if err := c.BodyParser(&request); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
+	}
+
+	// Validate the batch itself
+	if request.Mode == "" {
+		request.Mode = models.BatchModeAtomic
+	}
+	if errs := validation.Struct(&request); errs != nil {
+		return validationProblem(c, errs)
+	}
+	atomic := request.Mode == models.BatchModeAtomic
+
+	// Validate every operation before touching the database
+	response := models.ProductBatchResponse{Mode: request.Mode}
+	results := make([]models.ProductBatchResult, len(request.Operations))
+	var valid []models.ProductBatchOperation
+	var validIndexes []int
+	for i := range request.Operations {
+		operation := &request.Operations[i]
+		results[i] = models.ProductBatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+		if errs := validateBatchOperation(operation); errs != nil {
+			results[i].Status = fiber.StatusBadRequest
+			results[i].Error = errs.Summary()
+			results[i].Errors = errs
+			response.Failed++
+			continue
+		}
+		valid = append(valid, *operation)
+		validIndexes = append(validIndexes, i)
+	}
+
+	// An atomic batch is only run when every operation is valid
+	if atomic && response.Failed > 0 {
+		return invalidBatchProblem(c, results)
+	}
+
+	outcomes, err := h.products.ApplyBatch(valid, atomic)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	for j, outcome := range outcomes {
+		result := &results[validIndexes[j]]
+		if outcome.Err != nil {
+			setBatchError(result, outcome.Err)
+			response.Failed++
+			continue
+		}
+		result.ID = outcome.Product.ID
+		switch valid[j].Op {
+		case models.BatchCreate:
+			result.Status = fiber.StatusCreated
+			result.Product = &outcome.Product
+		case models.BatchUpdate:
+			result.Status = fiber.StatusOK
+			result.Product = &outcome.Product
+		default:
+			result.Status = fiber.StatusOK
+		}
+		response.Succeeded++
+	}
+
+	// A failed atomic batch only reports the operation that caused the rollback
+	if atomic && response.Failed > 0 {
+		failed := results[validIndexes[len(outcomes)-1]]
+		p := models.NewProblem(fiber.StatusConflict, models.ProblemBatchFailed,
+			fmt.Sprintf("Batch rolled back: operation %d failed", failed.Index))
+		p.Results = []models.ProductBatchResult{failed}
+		return sendProblem(c, p)
+	}
+
+	response.Results = results
+	return c.JSON(response)
+}
+
+// invalidBatchProblem rejects an atomic batch with invalid operations. The
+// errors of every operation are keyed by their path in the request, e.g.
+// operations[2].product.price, and the invalid operations are listed in results.
+func invalidBatchProblem(c *fiber.Ctx, results []models.ProductBatchResult) error {
+	errs := validation.Errors{}
+	var invalid []models.ProductBatchResult
+	for _, result := range results {
+		if result.Errors == nil {
+			continue
+		}
+		invalid = append(invalid, result)
+		for field, err := range result.Errors {
+			errs[fmt.Sprintf("operations[%d].%s", result.Index, field)] = err
+		}
+	}
+
+	p := models.NewProblem(fiber.StatusBadRequest, models.ProblemValidationFailed,
+		fmt.Sprintf("Batch rejected: %d of %d operations are invalid", len(invalid), len(results)))
+	p.Errors = errs
+	p.Results = invalid
+	return sendProblem(c, p)
+}
+
+// validateBatchOperation checks one operation against the validate tags of
+// the operation and its product, which are the same rules as the
+// single-product endpoints use, and returns the invalid fields, or nil.
+// Fields are named relative to the operation, e.g. product.price.
+func validateBatchOperation(operation *models.ProductBatchOperation) validation.Errors {
+	if errs := validation.Struct(operation); errs != nil {
+		return errs
+	}
+	if operation.Product == nil {
+		return nil
+	}
+	if err := operation.Product.NormalizeBarcode(); err != nil {
+		return validation.Errors{"product.barcode": {Code: "barcode", Message: "Invalid barcode: " + err.Error()}}
+	}
+	return nil
+}
+
+// setBatchError records why an operation failed, with the status its
+// single-product endpoint would have returned
+func setBatchError(result *models.ProductBatchResult, err error) {
+	result.Status, _ = errorStatus(err)
+	result.Error = errorDetail(err, "Product")
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		result.ConflictingID = conflict.ConflictingID
+	}
+}
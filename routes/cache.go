@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultCachePolicies maps cacheable routes to their default Cache-Control
+// header. Config.CachePolicies overrides them per route.
+var DefaultCachePolicies = map[string]string{
+	"products_list":    "no-cache",
+	"products_get":     "no-cache",
+	"products_search":  "no-cache",
+	"products_suggest": "public, max-age=30",
+}
+
+// cacheControl returns a middleware that adds the Cache-Control policy of a
+// route to successful and not-modified responses
+func (h *productHandlers) cacheControl(route string) fiber.Handler {
+	policy := h.config.CachePolicies[route]
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		status := c.Response().StatusCode()
+		if policy != "" && (status == fiber.StatusOK || status == fiber.StatusNotModified) {
+			c.Set(fiber.HeaderCacheControl, policy)
+		}
+		return nil
+	}
+}
+
+// setValidators adds the ETag and, when known, Last-Modified headers to the response
+func setValidators(c *fiber.Ctx, etag string, lastModified time.Time) {
+	c.Set(fiber.HeaderETag, etag)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since against the
+// current validators. If-None-Match uses weak comparison and takes
+// precedence; If-Modified-Since is only used when it is absent.
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if header := c.Get(fiber.HeaderIfNoneMatch); header != "" {
+		if strings.TrimSpace(header) == "*" {
+			return true
+		}
+		opaque := strings.TrimPrefix(etag, "W/")
+		for _, tag := range strings.Split(header, ",") {
+			if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == opaque {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := c.Get(fiber.HeaderIfModifiedSince); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// hasConditionalHeaders reports whether the request carries any GET preconditions
+func hasConditionalHeaders(c *fiber.Ctx) bool {
+	return c.Get(fiber.HeaderIfNoneMatch) != "" || c.Get(fiber.HeaderIfModifiedSince) != ""
+}
+
+// checkListValidators sets the ETag of a product listing and responds with
+// 304 when the client's copy is current. done reports whether a response has
+// been sent. Listings have no Last-Modified: permanently deleting a product
+// leaves no timestamp behind, so only the write counter in the ETag notices it.
+func (h *productHandlers) checkListValidators(c *fiber.Ctx) (done bool, err error) {
+	state, err := h.products.TableVersion()
+	if err != nil {
+		return true, repositoryProblem(c, err, "Product")
+	}
+
+	etag := listETag(c.Request().URI().QueryString(), strconv.FormatInt(state.Version, 10))
+	setValidators(c, etag, time.Time{})
+	if notModified(c, etag, time.Time{}) {
+		return true, c.SendStatus(fiber.StatusNotModified)
+	}
+	return false, nil
+}
+
+// listETag returns a weak entity tag for a listing, derived from the query
+// string and the version of the products table
+func listETag(query []byte, parts ...string) string {
+	hash := sha256.New()
+	hash.Write(query)
+	for _, part := range parts {
+		hash.Write([]byte{0})
+		hash.Write([]byte(part))
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:12]) + `"`
+}
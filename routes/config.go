@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"crypto/rand"
+	"log"
+	"maps"
+)
+
+// Config holds the settings of the routes
+type Config struct {
+	AdminToken    string            // Bearer token of the admin endpoints, empty disables them
+	CursorSecret  []byte            // Key signing pagination cursors, random if empty
+	CachePolicies map[string]string // Cache-Control overrides per route, see DefaultCachePolicies
+}
+
+// WithDefaults fills in the settings left empty. Without a cursor secret a
+// random key is generated, so cursors stop being valid after a restart.
+// The Setup functions call it themselves; call it first to share that key
+// between them.
+func (c Config) WithDefaults() Config {
+	policies := maps.Clone(DefaultCachePolicies)
+	maps.Copy(policies, c.CachePolicies)
+	c.CachePolicies = policies
+
+	if len(c.CursorSecret) == 0 {
+		c.CursorSecret = make([]byte, 32)
+		rand.Read(c.CursorSecret)
+		log.Println("CURSOR_SECRET not set, using a random key for pagination cursors")
+	}
+	return c
+}
@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"product-api/models"
+	"product-api/normalize"
+	"strings"
+	"time"
+)
+
+// cursorTTL is how long a cursor stays valid after the page that returned it
+const cursorTTL = 24 * time.Hour
+
+var (
+	// errInvalidCursor is returned when a cursor is malformed or its signature does not match
+	errInvalidCursor = errors.New("invalid cursor")
+	// errCursorExpired is returned when a cursor is older than cursorTTL
+	errCursorExpired = errors.New("cursor has expired, start again with paginate=cursor")
+	// errCursorMismatch is returned when a cursor is sent with other filters than it was issued for
+	errCursorMismatch = errors.New("cursor was issued for different filters")
+)
+
+// cursorPayload is the signed content of a pagination cursor
+type cursorPayload struct {
+	UpdatedAt time.Time `json:"u"`
+	ID        uint      `json:"i"`
+	IssuedAt  int64     `json:"t"` // Unix time the cursor was created
+	Filters   string    `json:"f"` // cursorFilterHash of the query it was issued for
+}
+
+// encodeCursor turns a keyset position into an opaque token signed with
+// secret and bound to the filters of query
+func encodeCursor(secret []byte, query models.ProductQuery, pos models.KeysetPosition, now time.Time) string {
+	payload, _ := json.Marshal(cursorPayload{
+		UpdatedAt: pos.UpdatedAt,
+		ID:        pos.ID,
+		IssuedAt:  now.Unix(),
+		Filters:   cursorFilterHash(query),
+	})
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(signCursor(secret, body))
+}
+
+// decodeCursor verifies a token created by encodeCursor for the same filters
+// and returns its position
+func decodeCursor(secret []byte, token string, query models.ProductQuery, now time.Time) (models.KeysetPosition, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return models.KeysetPosition{}, errInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, signCursor(secret, body)) {
+		return models.KeysetPosition{}, errInvalidCursor
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return models.KeysetPosition{}, errInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return models.KeysetPosition{}, errInvalidCursor
+	}
+	if now.Sub(time.Unix(payload.IssuedAt, 0)) > cursorTTL {
+		return models.KeysetPosition{}, errCursorExpired
+	}
+	if payload.Filters != cursorFilterHash(query) {
+		return models.KeysetPosition{}, errCursorMismatch
+	}
+	return models.KeysetPosition{UpdatedAt: payload.UpdatedAt, ID: payload.ID}, nil
+}
+
+// cursorFilterHash digests the filters and sort order of a query in the form
+// the repository applies them. Paging settings are left out, so the page
+// size may change between pages.
+func cursorFilterHash(query models.ProductQuery) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "name=%q;sku=%q;barcode=%q", normalize.Fold(query.Name), query.SKU, query.Barcode)
+	if query.MinPrice != nil {
+		fmt.Fprintf(&b, ";min_price=%v", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		fmt.Fprintf(&b, ";max_price=%v", *query.MaxPrice)
+	}
+	if query.MinQuantity != nil {
+		fmt.Fprintf(&b, ";min_quantity=%d", *query.MinQuantity)
+	}
+	if query.MaxQuantity != nil {
+		fmt.Fprintf(&b, ";max_quantity=%d", *query.MaxQuantity)
+	}
+	for _, field := range query.Sort {
+		fmt.Fprintf(&b, ";sort=%v", field)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// signCursor computes the HMAC-SHA256 signature of an encoded cursor body
+func signCursor(secret []byte, body string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"errors"
+	"product-api/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 3, 31, 2, 30, 0, 0, time.UTC)
+	pos := models.KeysetPosition{UpdatedAt: now.Add(-time.Hour), ID: 42}
+	query := models.ProductQuery{Limit: 20, Name: "Lamba"}
+
+	token := encodeCursor(secret, query, pos, now)
+
+	// The page size may change between pages and the name filter matches folded
+	query.Limit = 50
+	query.Name = "lamba"
+	got, err := decodeCursor(secret, token, query, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !got.UpdatedAt.Equal(pos.UpdatedAt) || got.ID != pos.ID {
+		t.Errorf("decodeCursor = %+v, want %+v", got, pos)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 3, 31, 2, 30, 0, 0, time.UTC)
+	pos := models.KeysetPosition{UpdatedAt: now, ID: 7}
+	minPrice := 10.0
+	query := models.ProductQuery{Name: "a", MinPrice: &minPrice}
+	token := encodeCursor(secret, query, pos, now)
+	body, sig, _ := strings.Cut(token, ".")
+
+	otherPrice := 20.0
+	tests := []struct {
+		name  string
+		token string
+		query models.ProductQuery
+		now   time.Time
+		want  error
+	}{
+		{"malformed", "not-a-cursor", query, now, errInvalidCursor},
+		{"tampered body", encodeCursor(secret, query, models.KeysetPosition{ID: 8}, now)[:len(body)] + "." + sig, query, now, errInvalidCursor},
+		{"tampered signature", body + "." + sig[:len(sig)-2] + "AA", query, now, errInvalidCursor},
+		{"other secret", encodeCursor([]byte("other"), query, pos, now), query, now, errInvalidCursor},
+		{"expired", token, query, now.Add(cursorTTL + time.Second), errCursorExpired},
+		{"other name", token, models.ProductQuery{Name: "b", MinPrice: &minPrice}, now, errCursorMismatch},
+		{"other price", token, models.ProductQuery{Name: "a", MinPrice: &otherPrice}, now, errCursorMismatch},
+		{"filter removed", token, models.ProductQuery{Name: "a"}, now, errCursorMismatch},
+		{"sort added", token, models.ProductQuery{Name: "a", MinPrice: &minPrice, Sort: []models.SortField{{Column: "price"}}}, now, errCursorMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(secret, tt.token, tt.query, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("decodeCursor error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"product-api/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// versionETag returns the strong entity tag of a product version
+func versionETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// productETag returns the strong entity tag of the current version of a product
+func productETag(product models.Product) string {
+	return versionETag(product.Version)
+}
+
+// setProductValidators adds the ETag and Last-Modified headers for a product to the response
+func setProductValidators(c *fiber.Ctx, product models.Product) {
+	setValidators(c, productETag(product), timeOrZero(product.UpdatedAt))
+}
+
+// timeOrZero dereferences an optional timestamp
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// ifMatch reports whether the If-Match request header allows modifying the
+// product. A missing header or "*" always matches; otherwise one of the
+// listed entity tags must be strongly equal to the product's ETag, so weak
+// tags never match.
+func ifMatch(c *fiber.Ctx, product models.Product) bool {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return true
+	}
+
+	etag := productETag(product)
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed responds with 412 when the client's version of a product is stale
+func preconditionFailed(c *fiber.Ctx) error {
+	return problem(c, fiber.StatusPreconditionFailed, models.ProblemPreconditionFailed, "Product has been modified, fetch the latest version and retry")
+}
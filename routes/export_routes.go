@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"product-api/exporter"
+	"product-api/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportProductsHandler handles GET requests to download the catalog
+// @Summary Export products
+// @Description Download all products matching the listing filters as CSV, NDJSON or XLSX.
+// @Description Rows are streamed from the database as they are read, so the whole catalog
+// @Description can be exported. Pagination parameters are ignored.
+// @Tags products
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format (default csv)" Enums(csv, ndjson, xlsx)
+// @Param columns query string false "Comma separated columns to export, in order (default all): id, name, description, price, quantity, sku, barcode, barcode_format, gtin, version, created_at, updated_at"
+// @Param name query string false "Filter by partial product name"
+// @Param sku query string false "Filter by SKU"
+// @Param barcode query string false "Filter by barcode"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_quantity query int false "Minimum quantity"
+// @Param max_quantity query int false "Maximum quantity"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (e.g. price,-created_at)"
+// @Success 200 {file} file
+// @Failure 400 {object} models.Problem
+// @Router /products/export [get]
+func (h *productHandlers) exportProductsHandler(c *fiber.Ctx) error {
+	// Parse format, columns, filter and sort parameters
+	options, err := parseExportOptions(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, exporter.ContentType(options.Format))
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+exportFilename(options.Format)+`"`)
+
+	// The status is sent before the first row is read, so errors while
+	// streaming can only be logged; the truncated file is then incomplete
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		err := h.exportProducts(w, options, nil)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			log.Printf("Export failed: %v", err)
+		}
+	})
+	return nil
+}
+
+// exportOptions are the parameters of an export request
+type exportOptions struct {
+	Format  string              `json:"format"`
+	Columns []string            `json:"columns"`
+	Query   models.ProductQuery `json:"query"`
+}
+
+// parseExportOptions reads the format, columns, filter and sort parameters
+func parseExportOptions(c *fiber.Ctx) (exportOptions, error) {
+	options := exportOptions{Format: c.Query("format", exporter.CSV)}
+	if !exporter.IsFormat(options.Format) {
+		return options, errors.New("format must be csv, ndjson or xlsx")
+	}
+
+	var err error
+	if options.Columns, err = exporter.ParseColumns(c.Query("columns")); err != nil {
+		return options, err
+	}
+	if err := parseProductFilters(c, &options.Query); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// exportFilename is the file name offered for an export made now
+func exportFilename(format string) string {
+	return "products-" + time.Now().UTC().Format("20060102-150405") + "." + format
+}
+
+// exportProducts writes the products matching the options to w. A non-nil
+// progress function is called after every product; an error from it stops
+// the export.
+func (h *productHandlers) exportProducts(w io.Writer, options exportOptions, progress func(done int64) error) error {
+	writer, err := exporter.NewWriter(options.Format, w, options.Columns)
+	if err != nil {
+		return err
+	}
+
+	var done int64
+	err = h.products.Export(options.Query, func(product models.Product) error {
+		if err := writer.Write(product); err != nil {
+			return err
+		}
+		done++
+		if progress != nil {
+			return progress(done)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
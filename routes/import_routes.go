@@ -0,0 +1,196 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"product-api/importer"
+	"product-api/models"
+	"product-api/repository"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// importProductsHandler handles POST requests to import products from a CSV file
+// @Summary Import products from CSV
+// @Description Upsert products by SKU from an uploaded CSV file. Each row is validated like
+// @Description POST /products and rejected rows are reported with their line number and reason.
+// @Description Columns are matched to fields by name unless a mapping is given. Existing products
+// @Description keep the fields the file has no column for. With dry_run the report is produced
+// @Description without writing anything.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with a header row"
+// @Param mapping formData string false "JSON object mapping fields to column headers, e.g. {\"name\":\"Ürün Adı\",\"price\":\"Fiyat\"}"
+// @Param delimiter formData string false "Column delimiter (default ,)"
+// @Param dry_run formData bool false "Validate and report without writing"
+// @Success 200 {object} models.ProductImportReport
+// @Failure 400 {object} models.Problem
+// @Router /products/import [post]
+func (h *productHandlers) importProductsHandler(c *fiber.Ctx) error {
+	// Read import options and the uploaded file
+	options, err := parseImportOptions(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+	file, err := openImportFile(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+	defer file.Close()
+
+	rows, fields, err := importer.ReadCSV(file, options.Mapping, options.Delimiter)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, err.Error())
+	}
+
+	report, err := h.importProducts(rows, fields, options.DryRun, nil)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+	return c.JSON(report)
+}
+
+// importOptions are the form fields of an import request
+type importOptions struct {
+	Mapping   importer.Mapping `json:"mapping"`
+	Delimiter rune             `json:"delimiter"`
+	DryRun    bool             `json:"dry_run"`
+}
+
+// parseImportOptions reads the mapping, delimiter and dry_run form fields
+func parseImportOptions(c *fiber.Ctx) (importOptions, error) {
+	options := importOptions{Delimiter: ','}
+	var err error
+	if options.Mapping, err = importer.ParseMapping(c.FormValue("mapping")); err != nil {
+		return options, err
+	}
+
+	if value := c.FormValue("delimiter"); value != "" {
+		r, size := utf8.DecodeRuneInString(value)
+		if size != len(value) || r == '"' || r == '\r' || r == '\n' {
+			return options, errors.New("delimiter must be a single character")
+		}
+		options.Delimiter = r
+	}
+
+	options.DryRun = c.QueryBool("dry_run") || c.FormValue("dry_run") == "true"
+	return options, nil
+}
+
+// openImportFile opens the CSV file uploaded in the file field
+func openImportFile(c *fiber.Ctx) (multipart.File, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("A CSV file is required in the file field")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, errors.New("Cannot read uploaded file")
+	}
+	return file, nil
+}
+
+// importProducts validates parsed rows like addProductHandler, upserts the
+// valid ones by SKU and reports the outcome of every row. Existing products
+// only have the fields read from the file replaced. A non-nil progress
+// function is called as valid rows are imported, see ImportBySKU.
+func (h *productHandlers) importProducts(rows []importer.Row, fields []string, dryRun bool, progress func(done int64) error) (models.ProductImportReport, error) {
+	report := models.ProductImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]models.ProductImportRow, len(rows)),
+	}
+	for _, field := range importer.Fields {
+		if !slices.Contains(fields, field) {
+			report.UntouchedFields = append(report.UntouchedFields, field)
+		}
+	}
+
+	// Validate rows; a SKU may only appear once per file
+	var products []models.Product
+	var indexes []int
+	skuLines := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		result := &report.Rows[i]
+		*result = models.ProductImportRow{Line: row.Line, SKU: row.Product.SKU}
+
+		msg := row.Err
+		if msg == "" {
+			msg = validateProductCreateDTO(&row.Product).Summary()
+		}
+		if msg == "" && row.Product.SKU == "" {
+			msg = "SKU is required"
+		}
+		if line, ok := skuLines[row.Product.SKU]; msg == "" && ok {
+			msg = fmt.Sprintf("SKU already appears on line %d", line)
+		}
+		if msg != "" {
+			result.Action = models.ImportRejected
+			result.Reason = msg
+			continue
+		}
+
+		skuLines[row.Product.SKU] = row.Line
+		products = append(products, row.Product.ToProduct())
+		indexes = append(indexes, i)
+	}
+
+	// Progress counts rejected rows as done up front
+	if progress != nil {
+		rejected := int64(len(rows) - len(products))
+		report := progress
+		progress = func(done int64) error {
+			return report(rejected + done)
+		}
+	}
+
+	// Fields are named like the columns they are stored in
+	outcomes, err := h.products.ImportBySKU(products, fields, dryRun, progress)
+	if err != nil {
+		return models.ProductImportReport{}, err
+	}
+
+	for j, outcome := range outcomes {
+		result := &report.Rows[indexes[j]]
+		if outcome.Err != nil {
+			result.Action = models.ImportRejected
+			result.Reason, result.ConflictingID = importErrorReason(outcome.Err)
+			continue
+		}
+		result.ID = outcome.Product.ID
+		if outcome.Created {
+			result.Action = models.ImportCreated
+			if dryRun {
+				result.ID = 0
+			}
+		} else {
+			result.Action = models.ImportUpdated
+		}
+	}
+
+	for _, result := range report.Rows {
+		switch result.Action {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		default:
+			report.Rejected++
+		}
+	}
+	return report, nil
+}
+
+// importErrorReason describes why the repository rejected a row
+func importErrorReason(err error) (string, uint) {
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		return errorDetail(err, "Product"), conflict.ConflictingID
+	}
+	return errorDetail(err, "Product"), 0
+}
@@ -0,0 +1,281 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"product-api/jobs"
+	"product-api/models"
+	"product-api/repository"
+	"product-api/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// jobHandlers serve the job routes and run the product jobs
+type jobHandlers struct {
+	*productHandlers
+	manager *jobs.Manager // Runs the background jobs started through the job routes
+}
+
+// createImportJobHandler handles POST requests to import a CSV file in the background
+// @Summary Start a CSV import job
+// @Description Queue an import with the same form fields as POST /products/import.
+// @Description The import report becomes the job result once it finishes.
+// @Tags jobs
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with a header row"
+// @Param mapping formData string false "JSON object mapping fields to column headers"
+// @Param delimiter formData string false "Column delimiter (default ,)"
+// @Param dry_run formData bool false "Validate and report without writing"
+// @Success 202 {object} models.Job
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} models.Problem
+// @Router /jobs/imports [post]
+func (h *jobHandlers) createImportJobHandler(c *fiber.Ctx) error {
+	// Read import options and the uploaded file
+	options, err := parseImportOptions(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+	file, err := openImportFile(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+	defer file.Close()
+
+	job, err := h.manager.Enqueue(productImportJob, options, file)
+	return acceptedJob(c, job, err)
+}
+
+// createExportJobHandler handles POST requests to export products in the background
+// @Summary Start an export job
+// @Description Queue an export with the same query parameters as GET /products/export.
+// @Description The exported file becomes the job result once it finishes.
+// @Tags jobs
+// @Produce json
+// @Param format query string false "File format (default csv)" Enums(csv, ndjson, xlsx)
+// @Param columns query string false "Comma separated columns to export, in order (default all)"
+// @Param name query string false "Filter by partial product name"
+// @Param sku query string false "Filter by SKU"
+// @Param barcode query string false "Filter by barcode"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_quantity query int false "Minimum quantity"
+// @Param max_quantity query int false "Maximum quantity"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending"
+// @Success 202 {object} models.Job
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} models.Problem
+// @Router /jobs/exports [post]
+func (h *jobHandlers) createExportJobHandler(c *fiber.Ctx) error {
+	options, err := parseExportOptions(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	job, err := h.manager.Enqueue(productExportJob, options, nil)
+	return acceptedJob(c, job, err)
+}
+
+// createPriceChangeJobHandler handles POST requests to change prices in bulk
+// @Summary Start a bulk price change job
+// @Description Change the price of every product matching the listing filters, either by a
+// @Description percentage or by a fixed amount. Prices are rounded to cents and products whose
+// @Description price would drop to zero or below are skipped. The counts become the job result.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param change body models.PriceChange true "Exactly one of percent and amount"
+// @Param name query string false "Filter by partial product name"
+// @Param sku query string false "Filter by SKU"
+// @Param barcode query string false "Filter by barcode"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_quantity query int false "Minimum quantity"
+// @Param max_quantity query int false "Maximum quantity"
+// @Success 202 {object} models.Job
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} models.Problem
+// @Router /jobs/price-changes [post]
+func (h *jobHandlers) createPriceChangeJobHandler(c *fiber.Ctx) error {
+	// Parse request body
+	var options priceChangeOptions
+	if err := c.BodyParser(&options.Change); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
+	}
+
+	// Validate input
+	if errs := validation.Struct(&options.Change); errs != nil {
+		return validationProblem(c, errs)
+	}
+	if err := parseProductFilters(c, &options.Query); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	job, err := h.manager.Enqueue(priceChangeJob, options, nil)
+	return acceptedJob(c, job, err)
+}
+
+// createReindexJobHandler handles POST requests to rebuild the search data
+// @Summary Start a search reindex job
+// @Description Recompute the normalized text and GTIN of every product and rebuild the
+// @Description full-text and autocomplete indexes.
+// @Tags jobs
+// @Produce json
+// @Security AdminToken
+// @Success 202 {object} models.Job
+// @Header 202 {string} Location "URL of the job"
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Router /jobs/reindex [post]
+func (h *jobHandlers) createReindexJobHandler(c *fiber.Ctx) error {
+	job, err := h.manager.Enqueue(searchReindexJob, struct{}{}, nil)
+	return acceptedJob(c, job, err)
+}
+
+// getJobHandler handles GET requests to retrieve the status of a job
+// @Summary Get a job
+// @Description Retrieve the status and progress of a job. Once it has succeeded,
+// @Description result_url points to its result if it has one.
+// @Tags jobs
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /jobs/{id} [get]
+func (h *jobHandlers) getJobHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid job ID")
+	}
+
+	job, err := h.manager.Get(uint(id))
+	if err != nil {
+		return jobErrorResponse(c, err)
+	}
+	return c.JSON(jobResponse(job))
+}
+
+// getJobResultHandler handles GET requests to download the result of a job
+// @Summary Download a job result
+// @Description Download the file produced by a succeeded job
+// @Tags jobs
+// @Produce octet-stream
+// @Param id path int true "Job ID"
+// @Success 200 {file} file
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem "Job has not succeeded"
+// @Router /jobs/{id}/result [get]
+func (h *jobHandlers) getJobResultHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid job ID")
+	}
+
+	job, err := h.manager.Get(uint(id))
+	if err != nil {
+		return jobErrorResponse(c, err)
+	}
+	if job.Status == models.JobSucceeded && job.ResultName == "" {
+		return problem(c, fiber.StatusNotFound, models.ProblemNotFound, "Job has no result")
+	}
+
+	file, err := h.manager.Result(job)
+	if err != nil {
+		return jobErrorResponse(c, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return jobErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, job.ResultContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+job.ResultName+`"`)
+	return c.SendStream(file, int(info.Size()))
+}
+
+// cancelJobHandler handles POST requests to cancel a job
+// @Summary Cancel a job
+// @Description Cancel a queued or running job. A queued job is cancelled at once; a running
+// @Description job stops at its next progress update and then has the cancelled status.
+// @Tags jobs
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 202 {object} models.Job
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem "Job already finished"
+// @Router /jobs/{id}/cancel [post]
+func (h *jobHandlers) cancelJobHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid job ID")
+	}
+
+	job, err := h.manager.Cancel(uint(id))
+	if err != nil {
+		return jobErrorResponse(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(jobResponse(job))
+}
+
+// acceptedJob responds with 202 and the queued job, or with 500 if it could not be queued
+func acceptedJob(c *fiber.Ctx, job models.Job, err error) error {
+	if err != nil {
+		return problem(c, fiber.StatusInternalServerError, models.ProblemInternal, "Failed to queue job")
+	}
+	c.Location(jobURL(job.ID))
+	return c.Status(fiber.StatusAccepted).JSON(jobResponse(job))
+}
+
+// jobResponse adds the result URL to a job that has a result
+func jobResponse(job models.Job) models.Job {
+	if job.Status == models.JobSucceeded && job.ResultName != "" {
+		job.ResultURL = jobURL(job.ID) + "/result"
+	}
+	return job
+}
+
+// jobURL is the URL of a job
+func jobURL(id uint) string {
+	return fmt.Sprintf("/api/v1/jobs/%d", id)
+}
+
+// jobErrorResponse maps job manager errors to responses
+func jobErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return problem(c, fiber.StatusNotFound, models.ProblemNotFound, "Job not found")
+	case errors.Is(err, jobs.ErrFinished):
+		return problem(c, fiber.StatusConflict, models.ProblemConflict, "Job already finished")
+	case errors.Is(err, jobs.ErrResultNotReady):
+		return problem(c, fiber.StatusConflict, models.ProblemConflict, "Job has not succeeded, its result is not available")
+	default:
+		return problem(c, fiber.StatusInternalServerError, models.ProblemInternal, "Failed to retrieve job")
+	}
+}
+
+// SetupJobRoutes registers the product job types with the manager and
+// configures the job routes. The jobs work on the given product store.
+func SetupJobRoutes(app *fiber.App, manager *jobs.Manager, store repository.ProductStore, config Config) {
+	config = config.WithDefaults()
+	h := &jobHandlers{
+		productHandlers: &productHandlers{products: store, config: config},
+		manager:         manager,
+	}
+	h.registerProductJobs(manager)
+
+	jobRoutes := app.Group("/api/v1/jobs")
+	jobRoutes.Post("/imports", h.createImportJobHandler)                                   // POST /api/v1/jobs/imports
+	jobRoutes.Post("/exports", h.createExportJobHandler)                                   // POST /api/v1/jobs/exports
+	jobRoutes.Post("/price-changes", h.createPriceChangeJobHandler)                        // POST /api/v1/jobs/price-changes
+	jobRoutes.Post("/reindex", requireAdmin(config.AdminToken), h.createReindexJobHandler) // POST /api/v1/jobs/reindex
+	jobRoutes.Get("/:id", h.getJobHandler)                                                 // GET /api/v1/jobs/:id
+	jobRoutes.Get("/:id/result", h.getJobResultHandler)                                    // GET /api/v1/jobs/:id/result
+	jobRoutes.Post("/:id/cancel", h.cancelJobHandler)                                      // POST /api/v1/jobs/:id/cancel
+}
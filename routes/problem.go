@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"product-api/models"
+	"product-api/repository"
+	"product-api/validation"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// problem responds with an application/problem+json body
+func problem(c *fiber.Ctx, status int, code, detail string) error {
+	return sendProblem(c, models.NewProblem(status, code, detail))
+}
+
+// validationProblem responds with 400 and every invalid field. The detail
+// repeats the message of the first field in sorted order.
+func validationProblem(c *fiber.Ctx, errs validation.Errors) error {
+	first := errs[errs.Fields()[0]].Message
+	p := models.NewProblem(fiber.StatusBadRequest, models.ProblemValidationFailed, first)
+	if len(errs) > 1 {
+		p.Detail = fmt.Sprintf("%s (and %d more)", first, len(errs)-1)
+	}
+	p.Errors = errs
+	return sendProblem(c, p)
+}
+
+// retryAfterSeconds is the Retry-After sent while the database is unavailable
+const retryAfterSeconds = "5"
+
+// errorStatus maps a repository error to its HTTP status and problem code.
+// This is the only place where repository errors get a status.
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return fiber.StatusNotFound, models.ProblemNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return fiber.StatusConflict, models.ProblemDuplicateValue
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return fiber.StatusPreconditionFailed, models.ProblemPreconditionFailed
+	case errors.Is(err, repository.ErrUnavailable):
+		return fiber.StatusServiceUnavailable, models.ProblemUnavailable
+	default:
+		return fiber.StatusInternalServerError, models.ProblemInternal
+	}
+}
+
+// errorDetail describes a repository error for the client. subject names the
+// resource, e.g. "Product". Unexpected errors are not described, so internal
+// details never reach the client.
+func errorDetail(err error, subject string) string {
+	var conflict *repository.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return "A product with this " + uniqueFieldLabels[conflict.Field] + " already exists"
+	case errors.Is(err, repository.ErrNotFound):
+		return subject + " not found"
+	case errors.Is(err, repository.ErrConflict):
+		return subject + " already exists"
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return subject + " has been modified, fetch the latest version and retry"
+	case errors.Is(err, repository.ErrUnavailable):
+		return "The database is temporarily unavailable, retry later"
+	default:
+		return "An unexpected error occurred"
+	}
+}
+
+// repositoryProblem responds to an error returned by a repository with the
+// status from errorStatus. Server side errors are logged, and 503 responses
+// tell the client when to retry.
+func repositoryProblem(c *fiber.Ctx, err error, subject string) error {
+	status, code := errorStatus(err)
+	if status >= fiber.StatusInternalServerError {
+		log.Printf("Request %s %s %s failed: %v", requestID(c), c.Method(), c.Path(), err)
+	}
+	if status == fiber.StatusServiceUnavailable {
+		c.Set(fiber.HeaderRetryAfter, retryAfterSeconds)
+	}
+
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		return conflictResponse(c, conflict)
+	}
+	return problem(c, status, code, errorDetail(err, subject))
+}
+
+// sendProblem fills in the request specific members of a problem and sends it
+func sendProblem(c *fiber.Ctx, p models.Problem) error {
+	p.Instance = c.Path()
+	p.RequestID = requestID(c)
+	return c.Status(p.Status).JSON(p, models.ProblemContentType)
+}
+
+// requestID returns the ID assigned to the request by the requestid middleware
+func requestID(c *fiber.Ctx) string {
+	id, _ := c.Locals("requestid").(string)
+	return id
+}
+
+// ErrorHandler turns errors returned by handlers and middleware into problem
+// responses. Fiber errors keep their status; any other error, including a
+// recovered panic, is mapped like a repository error by repositoryProblem,
+// so an unexpected one is logged and answered with a generic 500.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		return repositoryProblem(c, err, "Resource")
+	}
+
+	switch fiberErr.Code {
+	case fiber.StatusNotFound:
+		return problem(c, fiberErr.Code, models.ProblemNotFound, "Cannot "+c.Method()+" "+c.Path())
+	case fiber.StatusMethodNotAllowed:
+		return problem(c, fiberErr.Code, models.ProblemMethodNotAllowed, "Cannot "+c.Method()+" "+c.Path())
+	case fiber.StatusRequestEntityTooLarge:
+		return problem(c, fiberErr.Code, models.ProblemRequestTooLarge, fiberErr.Message)
+	case fiber.StatusUnsupportedMediaType:
+		return problem(c, fiberErr.Code, models.ProblemUnsupportedMediaType, fiberErr.Message)
+	}
+	if fiberErr.Code >= fiber.StatusInternalServerError {
+		log.Printf("Request %s %s %s failed: %v", requestID(c), c.Method(), c.Path(), err)
+		return problem(c, fiberErr.Code, models.ProblemInternal, "An unexpected error occurred")
+	}
+	return problem(c, fiberErr.Code, models.ProblemInvalidParameter, fiberErr.Message)
+}
+
+// SetupMiddleware adds the middleware every route relies on: a request ID,
+// echoed in the X-Request-ID header and in problem responses, and recovery
+// from panics, which are logged with their stack and answered with a 500
+// problem by ErrorHandler
+func SetupMiddleware(app *fiber.App) {
+	app.Use(requestid.New())
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace:  true,
+		StackTraceHandler: logPanic,
+	}))
+}
+
+// logPanic logs a recovered panic with the request ID and stack trace
+func logPanic(c *fiber.Ctx, e interface{}) {
+	log.Printf("Request %s %s %s panicked: %v\n%s", requestID(c), c.Method(), c.Path(), e, debug.Stack())
+}
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-api/exporter"
+	"product-api/importer"
+	"product-api/jobs"
+	"product-api/models"
+)
+
+// Job types run by the job manager
+const (
+	productImportJob = "product_import"
+	productExportJob = "product_export"
+	priceChangeJob   = "price_change"
+	searchReindexJob = "search_reindex"
+)
+
+// priceChangeOptions are the parameters of a price change job
+type priceChangeOptions struct {
+	Change models.PriceChange  `json:"change"`
+	Query  models.ProductQuery `json:"query"`
+}
+
+// registerProductJobs adds the product job types to the manager. Imports run
+// in a single transaction and exports and reindexing only rewrite derived
+// data, so those are resumable; a price change commits in batches and is
+// not, as running it again would change some prices twice.
+func (h *jobHandlers) registerProductJobs(manager *jobs.Manager) {
+	manager.Register(productImportJob, h.runImportJob, true)
+	manager.Register(productExportJob, h.runExportJob, true)
+	manager.Register(priceChangeJob, h.runPriceChangeJob, false)
+	manager.Register(searchReindexJob, h.runReindexJob, true)
+}
+
+// runImportJob imports the uploaded CSV file and stores the report as the result
+func (h *jobHandlers) runImportJob(ctx context.Context, run *jobs.Run) error {
+	var options importOptions
+	if err := run.Params(&options); err != nil {
+		return err
+	}
+	file, err := run.Input()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, fields, err := importer.ReadCSV(file, options.Mapping, options.Delimiter)
+	if err != nil {
+		return err
+	}
+	run.SetTotal(int64(len(rows)))
+
+	report, err := h.importProducts(rows, fields, options.DryRun, run.Progress)
+	if err != nil {
+		return err
+	}
+	return run.WriteResultJSON("import-report.json", report)
+}
+
+// runExportJob writes the export file as the result
+func (h *jobHandlers) runExportJob(ctx context.Context, run *jobs.Run) error {
+	var options exportOptions
+	if err := run.Params(&options); err != nil {
+		return err
+	}
+	total, err := h.products.Count(options.Query)
+	if err != nil {
+		return err
+	}
+	run.SetTotal(total)
+
+	w, err := run.CreateResult(exportFilename(options.Format), exporter.ContentType(options.Format))
+	if err != nil {
+		return err
+	}
+	return h.exportProducts(w, options, run.Progress)
+}
+
+// runPriceChangeJob changes the prices of the matching products and stores
+// the counts as the result
+func (h *jobHandlers) runPriceChangeJob(ctx context.Context, run *jobs.Run) error {
+	var options priceChangeOptions
+	if err := run.Params(&options); err != nil {
+		return err
+	}
+	total, err := h.products.Count(options.Query)
+	if err != nil {
+		return err
+	}
+	run.SetTotal(total)
+
+	result, err := h.products.ChangePrices(options.Query, options.Change, run.Progress)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("stopped after updating %d products", result.Updated)
+		}
+		return err
+	}
+	return run.WriteResultJSON("price-change.json", result)
+}
+
+// runReindexJob recomputes the search data of all products
+func (h *jobHandlers) runReindexJob(ctx context.Context, run *jobs.Run) error {
+	total, err := h.products.CountAll()
+	if err != nil {
+		return err
+	}
+	run.SetTotal(total)
+	return h.products.Reindex(run.Progress)
+}
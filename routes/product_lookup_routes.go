@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"net/url"
+	"product-api/gtin"
+	"product-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// getProductBySKUHandler handles GET requests to retrieve a product by SKU
+// @Summary Get a product by SKU
+// @Description Retrieve the product with the given Stock Keeping Unit
+// @Tags products
+// @Produce json
+// @Param sku path string true "SKU"
+// @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /products/by-sku/{sku} [get]
+func (h *productHandlers) getProductBySKUHandler(c *fiber.Ctx) error {
+	// Get SKU from URL
+	sku, err := url.PathUnescape(c.Params("sku"))
+	if err != nil || sku == "" {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid SKU")
+	}
+
+	// Get product from database
+	product, err := h.products.GetBySKU(sku)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	return sendProduct(c, product)
+}
+
+// getProductByBarcodeHandler handles GET requests to retrieve a product by barcode
+// @Summary Get a product by barcode
+// @Description Retrieve the product with the given barcode. The code may be given as
+// @Description EAN-8, UPC-E, UPC-A, EAN-13 or GTIN-14 and matches every equivalent form.
+// @Tags products
+// @Produce json
+// @Param code path string true "Barcode"
+// @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /products/by-barcode/{code} [get]
+func (h *productHandlers) getProductByBarcodeHandler(c *fiber.Ctx) error {
+	// Get barcode from URL
+	code, err := url.PathUnescape(c.Params("code"))
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid barcode")
+	}
+
+	// Reject codes that are not valid barcodes before looking them up
+	if _, err := gtin.ToGTIN14(code); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid barcode: "+err.Error())
+	}
+
+	// Get product from database
+	product, err := h.products.GetByBarcode(code)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	return sendProduct(c, product)
+}
+
+// sendProduct responds with a product and its validators, or with 304 when
+// the client's copy is current
+func sendProduct(c *fiber.Ctx, product models.Product) error {
+	setProductValidators(c, product)
+	if notModified(c, productETag(product), timeOrZero(product.UpdatedAt)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.JSON(product)
+}
@@ -0,0 +1,191 @@
+package routes
+
+import (
+	"fmt"
+	"math"
+	"product-api/models"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseProductQuery reads pagination, filter and sort parameters from the
+// query string. Either page/page_size or limit/offset may be used.
+func parseProductQuery(c *fiber.Ctx) (models.ProductQuery, error) {
+	var query models.ProductQuery
+	var err error
+
+	// Pagination
+	if c.Query("limit") != "" || c.Query("offset") != "" {
+		if query.Limit, err = parseIntParam(c, "limit", models.DefaultPageSize); err != nil {
+			return query, err
+		}
+		if query.Offset, err = parseIntParam(c, "offset", 0); err != nil {
+			return query, err
+		}
+	} else {
+		var page int
+		if page, err = parseIntParam(c, "page", 1); err != nil {
+			return query, err
+		}
+		if page < 1 {
+			return query, fmt.Errorf("page must be at least 1")
+		}
+		if query.Limit, err = parseIntParam(c, "page_size", models.DefaultPageSize); err != nil {
+			return query, err
+		}
+		query.Offset = (page - 1) * query.Limit
+	}
+	if query.Limit < 1 || query.Limit > models.MaxPageSize {
+		return query, fmt.Errorf("page size must be between 1 and %d", models.MaxPageSize)
+	}
+	if query.Offset < 0 {
+		return query, fmt.Errorf("offset cannot be negative")
+	}
+
+	// Filters and sorting
+	if err := parseProductFilters(c, &query); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// parseProductFilters reads the filter and sort parameters shared by the
+// listing and the export
+func parseProductFilters(c *fiber.Ctx, query *models.ProductQuery) error {
+	var err error
+
+	// Filters
+	query.Name = c.Query("name")
+	query.SKU = c.Query("sku")
+	query.Barcode = c.Query("barcode")
+	if query.MinPrice, err = parseFloatParam(c, "min_price"); err != nil {
+		return err
+	}
+	if query.MaxPrice, err = parseFloatParam(c, "max_price"); err != nil {
+		return err
+	}
+	if query.MinQuantity, err = parseOptionalIntParam(c, "min_quantity"); err != nil {
+		return err
+	}
+	if query.MaxQuantity, err = parseOptionalIntParam(c, "max_quantity"); err != nil {
+		return err
+	}
+
+	// Sorting, e.g. sort=price,-created_at
+	if query.Sort, err = parseSortParam(c.Query("sort")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseSortParam converts a comma separated list of sort keys into sort fields.
+// A leading "-" selects descending order.
+func parseSortParam(value string) ([]models.SortField, error) {
+	var fields []models.SortField
+	if value == "" {
+		return fields, nil
+	}
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		column, ok := models.ProductSortColumns[key]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort field: %q", key)
+		}
+		fields = append(fields, models.SortField{Column: column, Desc: desc})
+	}
+	return fields, nil
+}
+
+// parseIntParam reads an integer query parameter, using def when it is absent
+func parseIntParam(c *fiber.Ctx, name string, def int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	return n, nil
+}
+
+// parseOptionalIntParam reads an integer query parameter, returning nil when it is absent
+func parseOptionalIntParam(c *fiber.Ctx, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", name)
+	}
+	return &n, nil
+}
+
+// parseFloatParam reads a numeric query parameter, returning nil when it is absent
+func parseFloatParam(c *fiber.Ctx, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := parseFinite(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &f, nil
+}
+
+// parseFinite parses a number, rejecting NaN and infinities, which
+// strconv.ParseFloat accepts but which cannot be compared with prices
+func parseFinite(value string) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", value)
+	}
+	return f, nil
+}
+
+// parseFacetRequest reads the facets and price_buckets query parameters,
+// e.g. facets=price,stock&price_buckets=100,500,1000
+func parseFacetRequest(c *fiber.Ctx) (models.FacetRequest, error) {
+	var request models.FacetRequest
+	if value := c.Query("facets"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if !models.ProductFacetNames[name] {
+				return request, fmt.Errorf("invalid facet: %q", name)
+			}
+			request.Names = append(request.Names, name)
+		}
+	}
+
+	request.PriceBuckets = models.DefaultPriceBuckets
+	if value := c.Query("price_buckets"); value != "" {
+		parts := strings.Split(value, ",")
+		if len(parts) > models.MaxPriceBuckets {
+			return request, fmt.Errorf("at most %d price buckets are allowed", models.MaxPriceBuckets)
+		}
+		request.PriceBuckets = make([]float64, len(parts))
+		for i, part := range parts {
+			boundary, err := parseFinite(strings.TrimSpace(part))
+			if err != nil {
+				return request, fmt.Errorf("price_buckets must be numbers")
+			}
+			if i > 0 && boundary <= request.PriceBuckets[i-1] {
+				return request, fmt.Errorf("price_buckets must be in ascending order")
+			}
+			request.PriceBuckets[i] = boundary
+		}
+	}
+
+	return request, nil
+}
@@ -1,19 +1,24 @@
 package routes
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"product-api/jsonpatch"
 	"product-api/models"
 	"product-api/repository"
+	"product-api/validation"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// ProductRepository is used for database operations
-var productRepo *repository.ProductRepository
-
-// init initializes the product repository
-func init() {
-	productRepo = repository.NewProductRepository()
+// productHandlers serve the product routes of one application
+type productHandlers struct {
+	products repository.ProductStore
+	config   Config
 }
 
 // addProductHandler handles POST requests to create a new product
@@ -24,96 +29,187 @@ func init() {
 // @Produce json
 // @Param product body models.ProductCreateDTO true "Product to add"
 // @Success 201 {object} models.Product
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem "SKU or barcode already used, conflicting_id names the product"
 // @Router /products [post]
-func addProductHandler(c *fiber.Ctx) error {
+func (h *productHandlers) addProductHandler(c *fiber.Ctx) error {
 	// Parse request body into ProductCreateDTO struct
 	var productDTO models.ProductCreateDTO
 	if err := c.BodyParser(&productDTO); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Cannot parse JSON",
-		})
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
 	}
 
 	// Validate input
-	if productDTO.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
-	}
-
-	if productDTO.Price <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Price must be greater than 0",
-		})
-	}
-
-	if productDTO.Quantity < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Quantity cannot be negative",
-		})
+	if errs := validateProductCreateDTO(&productDTO); errs != nil {
+		return validationProblem(c, errs)
 	}
 
 	// Convert DTO to Product entity
 	product := productDTO.ToProduct()
 
 	// Add to database
-	err := productRepo.Create(&product)
+	err := h.products.Create(&product)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create product",
-		})
+		return repositoryProblem(c, err, "Product")
 	}
 
 	// Return success response with the created product
+	setProductValidators(c, product)
 	return c.Status(fiber.StatusCreated).JSON(product)
 }
 
-// getProductsHandler handles GET requests to retrieve all products
-// @Summary Get all products
-// @Description Retrieve all products from the system
+// getProductsHandler handles GET requests to list products
+// @Summary List products
+// @Description Retrieve a page of products with optional filters and sorting.
+// @Description Set paginate=cursor (or pass a cursor) to page by (updated_at, id) instead;
+// @Description the response is then a models.ProductCursorResponse.
 // @Tags products
 // @Produce json
-// @Success 200 {array} models.Product
+// @Param page query int false "Page number (1-based)"
+// @Param page_size query int false "Number of items per page (max 100)"
+// @Param limit query int false "Maximum number of items (alternative to page_size)"
+// @Param offset query int false "Number of items to skip (alternative to page)"
+// @Param name query string false "Filter by partial product name"
+// @Param sku query string false "Filter by SKU"
+// @Param barcode query string false "Filter by barcode"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_quantity query int false "Minimum quantity"
+// @Param max_quantity query int false "Maximum quantity"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (e.g. price,-created_at)"
+// @Param paginate query string false "Set to cursor to start a keyset scan" Enums(offset, cursor)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page, valid for 24 hours with the same filters"
+// @Param facets query string false "Comma separated facets to count over all matching products (price, stock)"
+// @Param price_buckets query string false "Ascending price bucket boundaries for the price facet (default 50,100,250,500,1000)"
+// @Param If-None-Match header string false "ETag of a previously returned listing"
+// @Success 200 {object} models.ProductListResponse
+// @Header 200 {string} ETag "Weak validator of the listing"
+// @Success 304 "Listing has not changed"
+// @Failure 400 {object} models.Problem
 // @Router /products [get]
-func getProductsHandler(c *fiber.Ctx) error {
-	products, err := productRepo.GetAll()
+func (h *productHandlers) getProductsHandler(c *fiber.Ctx) error {
+	// Parse pagination, filter and sort parameters
+	query, err := parseProductQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve products",
-		})
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	// Parse requested facets
+	facetRequest, err := parseFacetRequest(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	// Answer conditional requests without running the listing
+	if done, err := h.checkListValidators(c); done || err != nil {
+		return err
+	}
+
+	// Cursor mode walks the catalog in (updated_at, id) order
+	if c.Query("cursor") != "" || c.Query("paginate") == "cursor" {
+		if len(facetRequest.Names) > 0 {
+			return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "facets cannot be combined with cursor pagination")
+		}
+		return h.listProductsByCursor(c, query)
+	}
+
+	products, total, err := h.products.List(query)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	response := models.ProductListResponse{
+		Items: products,
+		Meta:  models.NewPageMeta(query, total),
+	}
+
+	// Compute facet counts over all matching products
+	if len(facetRequest.Names) > 0 {
+		if response.Facets, err = h.products.ListFacets(query, facetRequest); err != nil {
+			return repositoryProblem(c, err, "Product")
+		}
+	}
+
+	return c.JSON(response)
+}
+
+// listProductsByCursor serves a page of the keyset scan used in cursor mode
+func (h *productHandlers) listProductsByCursor(c *fiber.Ctx, query models.ProductQuery) error {
+	if query.Offset != 0 || len(query.Sort) > 0 {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "page, offset and sort cannot be combined with cursor pagination")
+	}
+
+	// Decode the position of the previous page, if any
+	var after *models.KeysetPosition
+	if token := c.Query("cursor"); token != "" {
+		pos, err := decodeCursor(h.config.CursorSecret, token, query, time.Now())
+		switch {
+		case errors.Is(err, errCursorExpired):
+			return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Cursor has expired, start again with paginate=cursor")
+		case errors.Is(err, errCursorMismatch):
+			return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Cursor was issued for different filters")
+		case err != nil:
+			return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid cursor")
+		}
+		after = &pos
 	}
 
-	return c.JSON(products)
+	products, hasMore, err := h.products.ListKeyset(query, after)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	response := models.ProductCursorResponse{Items: products, HasMore: hasMore}
+	if hasMore {
+		last := products[len(products)-1]
+		response.NextCursor = encodeCursor(h.config.CursorSecret, query, models.KeysetPosition{UpdatedAt: *last.UpdatedAt, ID: last.ID}, time.Now())
+	}
+	return c.JSON(response)
 }
 
 // getProductHandler handles GET requests to retrieve a specific product
 // @Summary Get a product by ID
-// @Description Retrieve a product by its ID
+// @Description Retrieve a product by its ID. The ETag header identifies the
+// @Description returned version and can be sent as If-Match when modifying it.
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param If-None-Match header string false "ETag of a previously returned version"
+// @Param If-Modified-Since header string false "Last-Modified of a previously returned version"
 // @Success 200 {object} models.Product
-// @Failure 404 {object} map[string]string
+// @Header 200 {string} ETag "Version of the product"
+// @Header 200 {string} Last-Modified "Time of the last update"
+// @Success 304 "Product has not changed"
+// @Failure 404 {object} models.Problem
 // @Router /products/{id} [get]
-func getProductHandler(c *fiber.Ctx) error {
+func (h *productHandlers) getProductHandler(c *fiber.Ctx) error {
 	// Get product ID from URL
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID",
-		})
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid product ID")
+	}
+
+	// Answer conditional requests from the version alone
+	if hasConditionalHeaders(c) {
+		version, err := h.products.GetVersion(uint(id))
+		if err != nil {
+			return repositoryProblem(c, err, "Product")
+		}
+		etag, lastModified := versionETag(version.Version), timeOrZero(version.UpdatedAt)
+		if notModified(c, etag, lastModified) {
+			setValidators(c, etag, lastModified)
+			return c.SendStatus(fiber.StatusNotModified)
+		}
 	}
 
 	// Get product from database
-	product, err := productRepo.GetByID(uint(id))
+	product, err := h.products.GetByID(uint(id))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Product not found",
-		})
+		return repositoryProblem(c, err, "Product")
 	}
 
+	setProductValidators(c, product)
 	return c.JSON(product)
 }
 
@@ -125,116 +221,286 @@ func getProductHandler(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Product ID"
 // @Param product body models.ProductUpdateDTO true "Updated product data"
+// @Param If-Match header string false "ETag of the version being replaced"
 // @Success 200 {object} models.Product
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Header 200 {string} ETag "Version of the updated product"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem "SKU or barcode already used, conflicting_id names the product"
+// @Failure 412 {object} models.Problem
 // @Router /products/{id} [put]
-func updateProductHandler(c *fiber.Ctx) error {
+func (h *productHandlers) updateProductHandler(c *fiber.Ctx) error {
 	// Get product ID from URL
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID",
-		})
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid product ID")
 	}
 
 	// Parse request body
 	var productDTO models.ProductUpdateDTO
 	if err := c.BodyParser(&productDTO); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Cannot parse JSON",
-		})
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
 	}
 
 	// Validate input
-	if productDTO.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+	if errs := validateProductUpdateDTO(&productDTO); errs != nil {
+		return validationProblem(c, errs)
 	}
 
-	if productDTO.Price <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Price must be greater than 0",
-		})
+	// Get existing product
+	product, err := h.products.GetByID(uint(id))
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
 	}
 
-	if productDTO.Quantity < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Quantity cannot be negative",
-		})
+	// Reject the update if the client edited an older version
+	if !ifMatch(c, product) {
+		return preconditionFailed(c)
+	}
+
+	// Apply changes from DTO
+	productDTO.ApplyToProduct(&product)
+
+	// Update product in database
+	err = h.products.Update(&product)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	setProductValidators(c, product)
+	return c.JSON(product)
+}
+
+// patchProductHandler handles PATCH requests to partially update a product
+// @Summary Partially update a product
+// @Description Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) to a product.
+// @Description The patch is applied to the fields of models.ProductUpdateDTO, the result is
+// @Description validated and only the changed columns are written.
+// @Tags products
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string false "ETag of the version being patched"
+// @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the patched product"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem "Failed test operation, or SKU or barcode already used"
+// @Failure 412 {object} models.Problem
+// @Failure 415 {object} models.Problem
+// @Router /products/{id} [patch]
+func (h *productHandlers) patchProductHandler(c *fiber.Ctx) error {
+	// Get product ID from URL
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid product ID")
 	}
 
 	// Get existing product
-	product, err := productRepo.GetByID(uint(id))
+	product, err := h.products.GetByID(uint(id))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Product not found",
-		})
+		return repositoryProblem(c, err, "Product")
 	}
 
-	// Apply changes from DTO
-	productDTO.ApplyToProduct(&product)
+	// Reject the patch if the client edited an older version
+	if !ifMatch(c, product) {
+		return preconditionFailed(c)
+	}
 
-	// Update product in database
-	err = productRepo.Update(product)
+	// Apply the patch to the current updatable fields
+	current := models.NewProductUpdateDTO(product)
+	document, err := json.Marshal(current)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update product",
-		})
+		return problem(c, fiber.StatusInternalServerError, models.ProblemInternal, "Failed to patch product")
+	}
+
+	var patched []byte
+	mediaType, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case jsonpatch.MergePatchType:
+		patched, err = jsonpatch.MergePatch(document, c.Body())
+	case jsonpatch.JSONPatchType:
+		patched, err = jsonpatch.Apply(document, c.Body())
+	default:
+		return problem(c, fiber.StatusUnsupportedMediaType, models.ProblemUnsupportedMediaType, "Content-Type must be "+jsonpatch.MergePatchType+" or "+jsonpatch.JSONPatchType)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return problem(c, fiber.StatusConflict, models.ProblemPatchTestFailed, err.Error())
+	}
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot apply patch: "+err.Error())
+	}
+
+	// Decode the patched document, rejecting fields that cannot be updated
+	var productDTO models.ProductUpdateDTO
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&productDTO); err != nil {
+		if errs := validation.DecodeErrors(err, &productDTO); errs != nil {
+			return validationProblem(c, errs)
+		}
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Patched product must be a JSON object")
+	}
+
+	// A new barcode without a new format has its format detected again
+	if productDTO.Barcode != product.Barcode && productDTO.BarcodeFormat == product.BarcodeFormat {
+		productDTO.BarcodeFormat = ""
+	}
+
+	// Validate the result
+	if errs := validateProductUpdateDTO(&productDTO); errs != nil {
+		return validationProblem(c, errs)
 	}
 
+	// Nothing to write when the patch did not change anything
+	columns := productDTO.ChangedColumns(product)
+	if len(columns) == 0 {
+		setProductValidators(c, product)
+		return c.JSON(product)
+	}
+
+	// Update only the changed columns
+	productDTO.ApplyToProduct(&product)
+	if err := h.products.UpdateColumns(&product, columns); err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	setProductValidators(c, product)
 	return c.JSON(product)
 }
 
+// uniqueFieldLabels are the names of unique product fields used in error messages
+var uniqueFieldLabels = map[string]string{
+	"sku":     "SKU",
+	"barcode": "barcode",
+}
+
+// conflictResponse responds with 409 naming the field and the product that
+// already uses the unique value
+func conflictResponse(c *fiber.Ctx, conflict *repository.ConflictError) error {
+	msg := "A product with this " + uniqueFieldLabels[conflict.Field] + " already exists"
+	p := models.NewProblem(fiber.StatusConflict, models.ProblemDuplicateValue, msg)
+	p.Errors = validation.Errors{conflict.Field: {Code: "duplicate", Message: msg + ": " + conflict.Value}}
+	p.ConflictingID = conflict.ConflictingID
+	return sendProblem(c, p)
+}
+
+// validateProductCreateDTO checks the fields of a new product against their
+// validate tags and returns the invalid ones, or nil. A valid barcode is
+// normalized in place.
+func validateProductCreateDTO(productDTO *models.ProductCreateDTO) validation.Errors {
+	update := models.ProductUpdateDTO(*productDTO)
+	errs := validateProductUpdateDTO(&update)
+	*productDTO = models.ProductCreateDTO(update)
+	return errs
+}
+
+// validateProductUpdateDTO checks the fields of an update against their
+// validate tags and returns the invalid ones, or nil. A valid barcode is
+// normalized in place.
+func validateProductUpdateDTO(productDTO *models.ProductUpdateDTO) validation.Errors {
+	if errs := validation.Struct(productDTO); errs != nil {
+		return errs
+	}
+	if err := productDTO.NormalizeBarcode(); err != nil {
+		return validation.Errors{"barcode": {Code: "barcode", Message: "Invalid barcode: " + err.Error()}}
+	}
+	return nil
+}
+
 // deleteProductHandler handles DELETE requests to remove a product
 // @Summary Delete a product
-// @Description Remove a product from the system
+// @Description Move a product to the trash, from where it can be restored. With permanent=true
+// @Description the product is removed for good, whether it is live or already in the trash;
+// @Description this requires the admin token.
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param permanent query bool false "Delete permanently instead of moving to the trash (admin only)"
+// @Param If-Match header string false "ETag of the version being deleted"
+// @Security AdminToken
 // @Success 200 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
 // @Router /products/{id} [delete]
-func deleteProductHandler(c *fiber.Ctx) error {
+func (h *productHandlers) deleteProductHandler(c *fiber.Ctx) error {
 	// Get product ID from URL
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID",
-		})
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid product ID")
+	}
+
+	// Permanent deletes bypass the trash and are reserved for admins
+	permanent := c.QueryBool("permanent")
+	if permanent {
+		if status, code, msg := authorizeAdmin(c, h.config.AdminToken); status != 0 {
+			return problem(c, status, code, msg)
+		}
+	}
+	getProduct, deleteProduct := h.products.GetByID, h.products.Delete
+	if permanent {
+		getProduct, deleteProduct = h.products.GetByIDUnscoped, h.products.DeletePermanently
+	}
+
+	// With If-Match, only delete the version the client has seen
+	var version uint
+	if header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch)); header != "" && header != "*" {
+		product, err := getProduct(uint(id))
+		if err != nil {
+			return repositoryProblem(c, err, "Product")
+		}
+		if !ifMatch(c, product) {
+			return preconditionFailed(c)
+		}
+		version = product.Version
 	}
 
 	// Delete product from database
-	err = productRepo.Delete(uint(id))
+	err = deleteProduct(uint(id), version)
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Product not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete product",
-		})
+		return repositoryProblem(c, err, "Product")
 	}
 
+	if permanent {
+		return c.JSON(fiber.Map{
+			"message": "Product permanently deleted",
+		})
+	}
 	return c.JSON(fiber.Map{
 		"message": "Product deleted successfully",
 	})
 }
 
-// SetupProductRoutes configures the product related routes
-func SetupProductRoutes(app *fiber.App) {
+// SetupProductRoutes configures the product related routes on top of the
+// given product store
+func SetupProductRoutes(app *fiber.App, store repository.ProductStore, config Config) {
+	h := &productHandlers{products: store, config: config.WithDefaults()}
+
 	// Group routes under /api/v1
 	api := app.Group("/api/v1")
 
 	// Product routes
 	products := api.Group("/products")
-	products.Post("/", addProductHandler)         // POST /api/v1/products
-	products.Get("/", getProductsHandler)         // GET /api/v1/products
-	products.Get("/:id", getProductHandler)       // GET /api/v1/products/:id
-	products.Put("/:id", updateProductHandler)    // PUT /api/v1/products/:id
-	products.Delete("/:id", deleteProductHandler) // DELETE /api/v1/products/:id
-} 
\ No newline at end of file
+	products.Post("/", h.addProductHandler)                                                         // POST /api/v1/products
+	products.Get("/", h.cacheControl("products_list"), h.getProductsHandler)                        // GET /api/v1/products
+	products.Get("/search", h.cacheControl("products_search"), h.searchProductsHandler)             // GET /api/v1/products/search
+	products.Get("/suggest", h.cacheControl("products_suggest"), h.suggestProductsHandler)          // GET /api/v1/products/suggest
+	products.Get("/export", h.exportProductsHandler)                                                // GET /api/v1/products/export
+	products.Get("/by-sku/:sku", h.cacheControl("products_get"), h.getProductBySKUHandler)          // GET /api/v1/products/by-sku/:sku
+	products.Post("/import", h.importProductsHandler)                                               // POST /api/v1/products/import
+	products.Post("/batch", h.batchProductsHandler)                                                 // POST /api/v1/products/batch
+	products.Get("/trash", h.getTrashHandler)                                                       // GET /api/v1/products/trash
+	products.Post("/:id/restore", h.restoreProductHandler)                                          // POST /api/v1/products/:id/restore
+	products.Put("/by-sku/:sku", h.upsertProductBySKUHandler)                                       // PUT /api/v1/products/by-sku/:sku
+	products.Get("/by-barcode/:code", h.cacheControl("products_get"), h.getProductByBarcodeHandler) // GET /api/v1/products/by-barcode/:code
+	products.Get("/:id", h.cacheControl("products_get"), h.getProductHandler)                       // GET /api/v1/products/:id
+	products.Put("/:id", h.updateProductHandler)                                                    // PUT /api/v1/products/:id
+	products.Patch("/:id", h.patchProductHandler)                                                   // PATCH /api/v1/products/:id
+	products.Delete("/:id", h.deleteProductHandler)                                                 // DELETE /api/v1/products/:id
+}
@@ -0,0 +1,88 @@
+package routes
+
+import (
+	"errors"
+	"net/url"
+	"product-api/models"
+	"product-api/repository"
+	"product-api/validation"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// upsertProductBySKUHandler handles PUT requests to create or replace a product by SKU
+// @Summary Create or replace a product by SKU
+// @Description Create the product with the given SKU, or replace all fields of the existing one.
+// @Description A soft-deleted product with the SKU is restored with the new values instead of
+// @Description creating a duplicate. Repeating the same request does not change the product.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param sku path string true "SKU"
+// @Param product body models.ProductUpdateDTO true "Product data; sku may be omitted but must match the path"
+// @Param If-Match header string false "ETag of the version being replaced"
+// @Success 200 {object} models.Product "Existing product replaced"
+// @Success 201 {object} models.Product "Product created or restored"
+// @Header 200,201 {string} ETag "Version of the product"
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem "Barcode already used, conflicting_id names the product"
+// @Failure 412 {object} models.Problem
+// @Router /products/by-sku/{sku} [put]
+func (h *productHandlers) upsertProductBySKUHandler(c *fiber.Ctx) error {
+	// Get SKU from URL
+	sku, err := url.PathUnescape(c.Params("sku"))
+	if err != nil || strings.TrimSpace(sku) == "" {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid SKU")
+	}
+
+	// Parse request body
+	var productDTO models.ProductUpdateDTO
+	if err := c.BodyParser(&productDTO); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
+	}
+
+	// The SKU in the body, if any, must match the URL
+	if productDTO.SKU != "" && productDTO.SKU != sku {
+		return validationProblem(c, validation.Errors{"sku": {Code: "mismatch", Message: "SKU in body does not match SKU in URL"}})
+	}
+	productDTO.SKU = sku
+
+	// Validate input
+	if errs := validateProductUpdateDTO(&productDTO); errs != nil {
+		return validationProblem(c, errs)
+	}
+
+	// With If-Match, only replace the version the client has seen
+	var version uint
+	if header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch)); header != "" {
+		existing, err := h.products.GetBySKU(sku)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return repositoryProblem(c, err, "Product")
+		}
+		if err != nil || !ifMatch(c, existing) {
+			return preconditionFailed(c)
+		}
+		version = existing.Version
+	}
+
+	// Create or replace the product
+	var product models.Product
+	productDTO.ApplyToProduct(&product)
+	created, err := h.products.UpsertBySKU(&product, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Only happens with If-Match, for a product that is gone
+		return preconditionFailed(c)
+	}
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	setProductValidators(c, product)
+	if created {
+		c.Location("/api/v1/products/" + strconv.FormatUint(uint64(product.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(product)
+	}
+	return c.JSON(product)
+}
@@ -0,0 +1,197 @@
+package routes
+
+import (
+	"errors"
+	"product-api/models"
+	"product-api/repository"
+	"product-api/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// searchConfigHandlers serve the search synonym and ranking routes
+type searchConfigHandlers struct {
+	searchConfig *repository.SearchConfigRepository
+}
+
+// getSynonymsHandler handles GET requests to list search synonyms
+// @Summary List search synonyms
+// @Description Retrieve the synonym dictionary used by product search
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} models.Synonym
+// @Failure 401 {object} models.Problem
+// @Router /admin/search/synonyms [get]
+func (h *searchConfigHandlers) getSynonymsHandler(c *fiber.Ctx) error {
+	synonyms, err := h.searchConfig.ListSynonyms()
+	if err != nil {
+		return repositoryProblem(c, err, "Synonym")
+	}
+
+	return c.JSON(synonyms)
+}
+
+// addSynonymHandler handles POST requests to create a search synonym
+// @Summary Create a search synonym
+// @Description Add a term whose synonyms are searched as well. Terms are stored case and accent folded.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param synonym body models.SynonymDTO true "Synonym to add"
+// @Success 201 {object} models.Synonym
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /admin/search/synonyms [post]
+func (h *searchConfigHandlers) addSynonymHandler(c *fiber.Ctx) error {
+	var synonymDTO models.SynonymDTO
+	if err := c.BodyParser(&synonymDTO); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
+	}
+
+	// Validate input
+	if errs := validation.Struct(&synonymDTO); errs != nil {
+		return validationProblem(c, errs)
+	}
+
+	var synonym models.Synonym
+	synonymDTO.ApplyToSynonym(&synonym)
+
+	// Each term may only have one entry
+	if _, err := h.searchConfig.GetSynonymByTerm(synonym.Term); err == nil {
+		return problem(c, fiber.StatusConflict, models.ProblemDuplicateValue, "Synonyms for this term already exist")
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return repositoryProblem(c, err, "Synonym")
+	}
+
+	if err := h.searchConfig.CreateSynonym(&synonym); err != nil {
+		return repositoryProblem(c, err, "Synonym")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(synonym)
+}
+
+// updateSynonymHandler handles PUT requests to replace a search synonym
+// @Summary Update a search synonym
+// @Description Replace the term and synonyms of an existing entry
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Synonym ID"
+// @Param synonym body models.SynonymDTO true "Updated synonym"
+// @Success 200 {object} models.Synonym
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /admin/search/synonyms/{id} [put]
+func (h *searchConfigHandlers) updateSynonymHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid synonym ID")
+	}
+
+	var synonymDTO models.SynonymDTO
+	if err := c.BodyParser(&synonymDTO); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
+	}
+
+	// Validate input
+	if errs := validation.Struct(&synonymDTO); errs != nil {
+		return validationProblem(c, errs)
+	}
+
+	synonym, err := h.searchConfig.GetSynonym(uint(id))
+	if err != nil {
+		return repositoryProblem(c, err, "Synonym")
+	}
+	synonymDTO.ApplyToSynonym(&synonym)
+
+	// The new term must not belong to another entry
+	existing, err := h.searchConfig.GetSynonymByTerm(synonym.Term)
+	if err == nil && existing.ID != synonym.ID {
+		return problem(c, fiber.StatusConflict, models.ProblemDuplicateValue, "Synonyms for this term already exist")
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return repositoryProblem(c, err, "Synonym")
+	}
+
+	if err := h.searchConfig.UpdateSynonym(synonym); err != nil {
+		return repositoryProblem(c, err, "Synonym")
+	}
+
+	return c.JSON(synonym)
+}
+
+// deleteSynonymHandler handles DELETE requests to remove a search synonym
+// @Summary Delete a search synonym
+// @Description Remove an entry from the synonym dictionary
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Synonym ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} models.Problem
+// @Router /admin/search/synonyms/{id} [delete]
+func (h *searchConfigHandlers) deleteSynonymHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid synonym ID")
+	}
+
+	if err := h.searchConfig.DeleteSynonym(uint(id)); err != nil {
+		return repositoryProblem(c, err, "Synonym")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Synonym deleted successfully",
+	})
+}
+
+// getSearchRankingHandler handles GET requests to read the search ranking rules
+// @Summary Get search ranking rules
+// @Description Retrieve the boosts applied to product search results
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} models.SearchRanking
+// @Router /admin/search/ranking [get]
+func (h *searchConfigHandlers) getSearchRankingHandler(c *fiber.Ctx) error {
+	ranking, err := h.searchConfig.GetRanking()
+	if err != nil {
+		return repositoryProblem(c, err, "Search ranking")
+	}
+
+	return c.JSON(ranking)
+}
+
+// updateSearchRankingHandler handles PUT requests to change the search ranking rules
+// @Summary Update search ranking rules
+// @Description Replace the boosts applied to product search results
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param ranking body models.SearchRanking true "Ranking rules"
+// @Success 200 {object} models.SearchRanking
+// @Failure 400 {object} models.Problem
+// @Router /admin/search/ranking [put]
+func (h *searchConfigHandlers) updateSearchRankingHandler(c *fiber.Ctx) error {
+	var ranking models.SearchRanking
+	if err := c.BodyParser(&ranking); err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemMalformedBody, "Cannot parse JSON")
+	}
+
+	// Validate input
+	if errs := validation.Struct(&ranking); errs != nil {
+		return validationProblem(c, errs)
+	}
+
+	if err := h.searchConfig.SaveRanking(&ranking); err != nil {
+		return repositoryProblem(c, err, "Search ranking")
+	}
+
+	return c.JSON(ranking)
+}
@@ -0,0 +1,106 @@
+package routes
+
+import (
+	"fmt"
+	"product-api/models"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Default and maximum number of autocomplete suggestions
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// searchProductsHandler handles GET requests for full-text product search
+// @Summary Search products
+// @Description Full-text search over product names and descriptions, ranked by relevance.
+// @Description name_highlight and description_snippet are HTML: the product text is escaped and matched terms are wrapped in <mark> tags.
+// @Tags products
+// @Produce json
+// @Param q query string true "Search text"
+// @Param page query int false "Page number (1-based)"
+// @Param page_size query int false "Number of items per page (max 100)"
+// @Param limit query int false "Maximum number of items (alternative to page_size)"
+// @Param offset query int false "Number of items to skip (alternative to page)"
+// @Param name query string false "Filter by partial product name"
+// @Param sku query string false "Filter by SKU"
+// @Param barcode query string false "Filter by barcode"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_quantity query int false "Minimum quantity"
+// @Param max_quantity query int false "Maximum quantity"
+// @Param sort query string false "Sort fields overriding relevance order (e.g. price,-created_at)"
+// @Param facets query string false "Comma separated facets to count over all matching products (price, stock)"
+// @Param price_buckets query string false "Ascending price bucket boundaries for the price facet (default 50,100,250,500,1000)"
+// @Success 200 {object} models.ProductSearchResponse
+// @Failure 400 {object} models.Problem
+// @Router /products/search [get]
+func (h *productHandlers) searchProductsHandler(c *fiber.Ctx) error {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Search query is required")
+	}
+
+	// Parse pagination, filter and sort parameters
+	query, err := parseProductQuery(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	// Parse requested facets
+	facetRequest, err := parseFacetRequest(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	hits, total, err := h.products.Search(text, query)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	response := models.ProductSearchResponse{
+		Items: hits,
+		Meta:  models.NewPageMeta(query, total),
+	}
+
+	// Compute facet counts over all matching products
+	if len(facetRequest.Names) > 0 {
+		if response.Facets, err = h.products.SearchFacets(text, query, facetRequest); err != nil {
+			return repositoryProblem(c, err, "Product")
+		}
+	}
+
+	return c.JSON(response)
+}
+
+// suggestProductsHandler handles GET requests for search box autocomplete
+// @Summary Suggest products
+// @Description Suggest product names and SKUs starting with the given prefix, tolerating typos in names
+// @Tags products
+// @Produce json
+// @Param prefix query string true "Text typed so far"
+// @Param limit query int false "Maximum number of suggestions (default 10, max 50)"
+// @Success 200 {object} models.ProductSuggestResponse
+// @Failure 400 {object} models.Problem
+// @Router /products/suggest [get]
+func (h *productHandlers) suggestProductsHandler(c *fiber.Ctx) error {
+	prefix := strings.TrimSpace(c.Query("prefix"))
+	if prefix == "" {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Prefix is required")
+	}
+
+	limit, err := parseIntParam(c, "limit", defaultSuggestLimit)
+	if err != nil || limit < 1 || limit > maxSuggestLimit {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, fmt.Sprintf("limit must be between 1 and %d", maxSuggestLimit))
+	}
+
+	suggestions, err := h.products.Suggest(prefix, limit)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	return c.JSON(models.ProductSuggestResponse{Items: suggestions})
+}
@@ -1,13 +1,126 @@
 package routes
 
 import (
+	"encoding/json"
+	"fmt"
+	"product-api/docs"
+	"product-api/models"
+	"product-api/validation"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
-
-	_ "product-api/docs" // Import generated docs
+	"github.com/swaggo/swag"
 )
 
-// SetupSwaggerRoutes configures the Swagger UI route
+// validatedDocsCount numbers the swag instances registered by
+// SetupSwaggerRoutes, whose names must be unique
+var validatedDocsCount atomic.Int64
+
+// validatedModels are the request bodies whose validate tags are added to
+// their Swagger definitions. swag only understands some rules, such as
+// min, max and oneof; the rest, like gt, pattern and custom rules, are added
+// when the docs are served.
+var validatedModels = []interface{}{
+	models.ProductCreateDTO{},
+	models.ProductUpdateDTO{},
+	models.ProductBatchRequest{},
+	models.ProductBatchOperation{},
+	models.SynonymDTO{},
+	models.SearchRanking{},
+	models.PriceChange{},
+}
+
+// validatedDocs adds validation rules and the configured host to the
+// generated docs on first read
+type validatedDocs struct {
+	host string
+	once sync.Once
+	doc  string
+}
+
+// ReadDoc returns the generated docs with validation rules added
+func (d *validatedDocs) ReadDoc() string {
+	d.once.Do(func() {
+		info := *docs.SwaggerInfo
+		info.Host = d.host
+		d.doc = addValidationRules(info.ReadDoc())
+	})
+	return d.doc
+}
+
+// addValidationRules merges the schema of the validate tags of every
+// validated model into its definition. The docs are returned unchanged if
+// they cannot be parsed.
+func addValidationRules(doc string) string {
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+		return doc
+	}
+	definitions, _ := spec["definitions"].(map[string]interface{})
+
+	for _, model := range validatedModels {
+		definition, ok := definitions[reflect.TypeOf(model).String()].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		properties, _ := definition["properties"].(map[string]interface{})
+		description := validation.Describe(model)
+
+		for name, keywords := range description.Properties {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if ref, ok := property["$ref"]; ok {
+				// Keywords next to $ref are ignored, so wrap it like swag does
+				delete(property, "$ref")
+				property["allOf"] = []interface{}{map[string]interface{}{"$ref": ref}}
+			}
+			for keyword, value := range keywords {
+				items, isItems := value.(validation.Schema)
+				existing, hasItems := property[keyword].(map[string]interface{})
+				if isItems && hasItems {
+					for k, v := range items {
+						existing[k] = v
+					}
+					continue
+				}
+				property[keyword] = value
+			}
+		}
+
+		required, _ := definition["required"].([]interface{})
+		for _, name := range description.Required {
+			if !containsValue(required, name) {
+				required = append(required, name)
+			}
+		}
+		if len(required) > 0 {
+			definition["required"] = required
+		}
+	}
+
+	patched, err := json.Marshal(spec)
+	if err != nil {
+		return doc
+	}
+	return string(patched)
+}
+
+// containsValue reports whether a decoded JSON array contains a string
+func containsValue(values []interface{}, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupSwaggerRoutes configures the Swagger UI route, whose docs send requests to host
 // @title           Product API
 // @version         1.0
 // @description     This is a sample server for a product API.
@@ -22,11 +135,14 @@ import (
 
 // @host      localhost:8080
 // @BasePath  /api/v1
-func SetupSwaggerRoutes(app *fiber.App) {
-	// Route for Swagger UI
-	app.Get("/swagger/*", swagger.HandlerDefault)
+func SetupSwaggerRoutes(app *fiber.App, host string) {
+	// Route for Swagger UI, serving the docs with validation rules
+	config := swagger.ConfigDefault
+	config.InstanceName = fmt.Sprintf("validated-%d", validatedDocsCount.Add(1))
+	swag.Register(config.InstanceName, &validatedDocs{host: host})
+	app.Get("/swagger/*", swagger.New(config))
 	// Default route for redirecting to swagger if needed
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.Redirect("/swagger/index.html", fiber.StatusMovedPermanently)
 	})
-} 
\ No newline at end of file
+}
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"errors"
+	"product-api/models"
+	"product-api/repository"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// getTrashHandler handles GET requests to list soft-deleted products
+// @Summary List trashed products
+// @Description Retrieve a page of soft-deleted products, most recently deleted first.
+// @Description Accepts the same filters and sort fields as the product listing.
+// @Tags products
+// @Produce json
+// @Param page query int false "Page number (1-based)"
+// @Param page_size query int false "Number of items per page (max 100)"
+// @Param limit query int false "Maximum number of items (alternative to page_size)"
+// @Param offset query int false "Number of items to skip (alternative to page)"
+// @Param name query string false "Filter by partial product name"
+// @Param sku query string false "Filter by SKU"
+// @Param barcode query string false "Filter by barcode"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (e.g. price,-created_at)"
+// @Success 200 {object} models.ProductTrashResponse
+// @Failure 400 {object} models.Problem
+// @Router /products/trash [get]
+func (h *productHandlers) getTrashHandler(c *fiber.Ctx) error {
+	// Parse pagination, filter and sort parameters
+	query, err := parseProductQuery(c)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, err.Error())
+	}
+
+	products, total, err := h.products.ListTrash(query)
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	response := models.ProductTrashResponse{
+		Items: make([]models.TrashedProduct, len(products)),
+		Meta:  models.NewPageMeta(query, total),
+	}
+	for i, product := range products {
+		response.Items[i] = models.NewTrashedProduct(product)
+	}
+	return c.JSON(response)
+}
+
+// restoreProductHandler handles POST requests to restore a trashed product
+// @Summary Restore a trashed product
+// @Description Move a soft-deleted product out of the trash
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the restored product"
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem "SKU or barcode now used by another product, conflicting_id names it"
+// @Router /products/{id}/restore [post]
+func (h *productHandlers) restoreProductHandler(c *fiber.Ctx) error {
+	// Get product ID from URL
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return problem(c, fiber.StatusBadRequest, models.ProblemInvalidParameter, "Invalid product ID")
+	}
+
+	product, err := h.products.Restore(uint(id))
+	if errors.Is(err, repository.ErrNotFound) {
+		return problem(c, fiber.StatusNotFound, models.ProblemNotFound, "Product not found in trash")
+	}
+	if err != nil {
+		return repositoryProblem(c, err, "Product")
+	}
+
+	setProductValidators(c, product)
+	return c.JSON(product)
+}
@@ -0,0 +1,270 @@
+// Package suggest provides an in-memory, typo-tolerant autocomplete index
+// over product names and SKUs.
+package suggest
+
+import (
+	"product-api/normalize"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Entry is a product known to the index
+type Entry struct {
+	ID   uint   // Product ID
+	Name string // Product name as stored
+	SKU  string // Stock Keeping Unit
+}
+
+// Result is an entry matched by a suggestion query
+type Result struct {
+	Entry
+	Score float64 // Higher is better
+}
+
+// Scores given to the different kinds of matches
+const (
+	scoreNamePrefix = 3.0 // The whole name starts with the query
+	scoreSKUPrefix  = 3.0 // The SKU starts with the query
+	scoreWordPrefix = 2.0 // A word of the name starts with the query
+	scoreFuzzy      = 1.0 // Maximum score of a match with typos
+)
+
+// indexedEntry holds an entry together with its precomputed match keys
+type indexedEntry struct {
+	Entry
+	name     string   // Folded name
+	words    []string // Folded words of the name
+	sku      string   // Folded SKU
+	trigrams []string // Trigrams of the words, used to find fuzzy candidates
+}
+
+// Index is a thread-safe autocomplete index. The zero value is not usable,
+// create one with New.
+type Index struct {
+	mu       sync.RWMutex
+	entries  map[uint]*indexedEntry
+	trigrams map[string]map[uint]struct{}
+}
+
+// New creates an empty index
+func New() *Index {
+	return &Index{
+		entries:  make(map[uint]*indexedEntry),
+		trigrams: make(map[string]map[uint]struct{}),
+	}
+}
+
+// Rebuild replaces the contents of the index with the given entries
+func (idx *Index) Rebuild(entries []Entry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries = make(map[uint]*indexedEntry, len(entries))
+	idx.trigrams = make(map[string]map[uint]struct{})
+	for _, entry := range entries {
+		idx.add(entry)
+	}
+}
+
+// Upsert adds an entry or replaces the entry with the same ID
+func (idx *Index) Upsert(entry Entry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(entry.ID)
+	idx.add(entry)
+}
+
+// Remove deletes the entry with the given ID, if present
+func (idx *Index) Remove(id uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+// add indexes an entry; the caller must hold the write lock
+func (idx *Index) add(entry Entry) {
+	e := &indexedEntry{
+		Entry: entry,
+		name:  normalize.Fold(entry.Name),
+		sku:   normalize.Fold(entry.SKU),
+	}
+	e.words = splitWords(e.name)
+	seen := make(map[string]bool)
+	for _, word := range e.words {
+		for _, tri := range trigrams(word) {
+			if !seen[tri] {
+				seen[tri] = true
+				e.trigrams = append(e.trigrams, tri)
+			}
+		}
+	}
+
+	idx.entries[entry.ID] = e
+	for _, tri := range e.trigrams {
+		if idx.trigrams[tri] == nil {
+			idx.trigrams[tri] = make(map[uint]struct{})
+		}
+		idx.trigrams[tri][entry.ID] = struct{}{}
+	}
+}
+
+// remove drops an entry; the caller must hold the write lock
+func (idx *Index) remove(id uint) {
+	e, ok := idx.entries[id]
+	if !ok {
+		return
+	}
+	for _, tri := range e.trigrams {
+		delete(idx.trigrams[tri], id)
+		if len(idx.trigrams[tri]) == 0 {
+			delete(idx.trigrams, tri)
+		}
+	}
+	delete(idx.entries, id)
+}
+
+// Suggest returns up to limit entries whose name or SKU starts with the
+// prefix, tolerating a few typos in names. Matching ignores case and
+// diacritics, including the Turkish dotted and dotless I.
+func (idx *Index) Suggest(prefix string, limit int) []Result {
+	query := strings.TrimSpace(normalize.Fold(prefix))
+	if query == "" || limit <= 0 {
+		return []Result{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[uint]float64)
+
+	// Exact prefix matches on the name, its words and the SKU
+	for id, e := range idx.entries {
+		switch {
+		case strings.HasPrefix(e.name, query):
+			scores[id] = scoreNamePrefix
+		case e.sku != "" && strings.HasPrefix(e.sku, query):
+			scores[id] = scoreSKUPrefix
+		case hasWordPrefix(e.words, query):
+			scores[id] = scoreWordPrefix
+		}
+	}
+
+	// Fuzzy matches on names, using trigrams to find candidates
+	queryWords := splitWords(query)
+	if maxDist := maxDistance(query); maxDist > 0 && len(queryWords) > 0 {
+		last := queryWords[len(queryWords)-1]
+		for id := range idx.candidates(last) {
+			if _, ok := scores[id]; ok {
+				continue
+			}
+			e := idx.entries[id]
+			dist := prefixDistance(query, e.name)
+			for _, word := range e.words {
+				if d := prefixDistance(last, word); d < dist {
+					dist = d
+				}
+			}
+			if dist <= maxDist {
+				scores[id] = scoreFuzzy - float64(dist)/float64(len([]rune(query))+1)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{Entry: idx.entries[id].Entry, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return a.ID < b.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// candidates returns the IDs of entries sharing at least one trigram with the word
+func (idx *Index) candidates(word string) map[uint]struct{} {
+	ids := make(map[uint]struct{})
+	for _, tri := range trigrams(word) {
+		for id := range idx.trigrams[tri] {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// maxDistance returns the number of typos tolerated for a query
+func maxDistance(query string) int {
+	switch n := len([]rune(query)); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// hasWordPrefix reports whether any of the words starts with the prefix
+func hasWordPrefix(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWords splits folded text into words of letters and digits
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// trigrams returns the trigrams of a word padded at the start, so that the
+// beginning of words weighs more than their end
+func trigrams(word string) []string {
+	runes := append([]rune("  "), []rune(word)...)
+	result := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		result = append(result, string(runes[i:i+3]))
+	}
+	return result
+}
+
+// prefixDistance returns the smallest Levenshtein distance between query and
+// any prefix of text
+func prefixDistance(query, text string) int {
+	q, t := []rune(query), []rune(text)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(q); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if q[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	best := prev[0]
+	for _, d := range prev[1:] {
+		best = min(best, d)
+	}
+	return best
+}
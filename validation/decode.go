@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DecodeErrors turns an error from decoding JSON into v, a pointer to a
+// struct, into the field that could not be decoded, so that clients get the
+// same field-keyed errors as from Struct instead of messages naming Go types.
+// It returns nil if the error is not about a single field, e.g. a syntax error.
+func DecodeErrors(err error, v interface{}) Errors {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		names := strings.Split(typeErr.Field, ".")
+		label := labelOfPath(reflect.TypeOf(v), names)
+		return Errors{fieldPath(names): {Code: "type", Message: label + " must be " + describeType(typeErr.Type)}}
+	}
+
+	// The decoder reports fields rejected by DisallowUnknownFields only as text
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name = strings.Trim(name, `"`)
+		return Errors{name: {Code: "unknown", Message: fmt.Sprintf("%s is not a known field", name)}}
+	}
+	return nil
+}
+
+// fieldPath turns the dotted path of a decode error, which names slice
+// elements by a bare index as in operations.2.product, into the form used by
+// Struct, operations[2].product
+func fieldPath(names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		if isIndex(name) {
+			b.WriteString("[" + name + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(name)
+	}
+	return b.String()
+}
+
+// isIndex reports whether a path element is a slice index
+func isIndex(name string) bool {
+	return name != "" && strings.Trim(name, "0123456789") == ""
+}
+
+// labelOfPath returns the label of the field at a JSON path in t, falling
+// back to the label derived from the last name of the path
+func labelOfPath(t reflect.Type, names []string) string {
+	names = slices.DeleteFunc(slices.Clone(names), isIndex)
+	for i, name := range names {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		index := slices.IndexFunc(fieldsOf(t), func(field structField) bool { return field.name == name })
+		if index < 0 {
+			break
+		}
+		field := fieldsOf(t)[index]
+		if i == len(names)-1 {
+			return field.label
+		}
+		t = t.Field(field.index).Type
+	}
+	return labelOf(names[len(names)-1])
+}
+
+// describeType names the kind of JSON value a Go type is decoded from
+func describeType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// init registers the built-in rules
+func init() {
+	Register("required", Rule{Check: required, Always: true})
+	Register("required_with", Rule{Check: requiredWith, Always: true})
+	Register("required_without", Rule{Check: requiredWithout, Always: true})
+	Register("required_if", Rule{Check: requiredIf, Always: true})
+	Register("excluded_with", Rule{Check: excludedWith})
+	Register("excluded_if", Rule{Check: excludedIf})
+
+	Register("gt", compare(func(v, p float64) bool { return v > p }, "greater than %s", "longer than %s characters", "more than %s items", minimumSchema(true)))
+	Register("gte", compare(func(v, p float64) bool { return v >= p }, "at least %s", "at least %s characters long", "at least %s items", minimumSchema(false)))
+	Register("min", compare(func(v, p float64) bool { return v >= p }, "at least %s", "at least %s characters long", "at least %s items", minimumSchema(false)))
+	Register("lt", compare(func(v, p float64) bool { return v < p }, "less than %s", "shorter than %s characters", "fewer than %s items", maximumSchema(true)))
+	Register("lte", compare(func(v, p float64) bool { return v <= p }, "at most %s", "at most %s characters long", "at most %s items", maximumSchema(false)))
+	Register("max", compare(func(v, p float64) bool { return v <= p }, "at most %s", "at most %s characters long", "at most %s items", maximumSchema(false)))
+
+	Register("oneof", Rule{Check: oneOf, Schema: oneOfSchema})
+	Register("pattern", Rule{Check: pattern, Schema: func(schema Schema, param string, t reflect.Type) {
+		schema["pattern"] = param
+	}})
+}
+
+// required fails unless the field is present, see Field.Present
+func required(f Field) string {
+	if !f.Present() {
+		return f.Label + " is required"
+	}
+	return ""
+}
+
+// requiredWith, as required_with=other, requires the field when other is present
+func requiredWith(f Field) string {
+	if f.Sibling(f.Param).Present() && !f.Present() {
+		return fmt.Sprintf("%s is required when %s is set", f.Label, f.Param)
+	}
+	return ""
+}
+
+// requiredWithout, as required_without=other, requires the field when other is missing
+func requiredWithout(f Field) string {
+	if !f.Sibling(f.Param).Present() && !f.Present() {
+		return fmt.Sprintf("%s is required when %s is not set", f.Label, f.Param)
+	}
+	return ""
+}
+
+// requiredIf, as required_if=other value..., requires the field when other
+// has one of the values
+func requiredIf(f Field) string {
+	other, values := splitCondition(f.Param)
+	if matches(f.Sibling(other), values) && !f.Present() {
+		return fmt.Sprintf("%s is required when %s is %s", f.Label, other, strings.Join(values, " or "))
+	}
+	return ""
+}
+
+// excludedWith, as excluded_with=other, forbids the field when other is present
+func excludedWith(f Field) string {
+	if f.Sibling(f.Param).Present() && f.Present() {
+		return fmt.Sprintf("%s must not be set when %s is set", f.Label, f.Param)
+	}
+	return ""
+}
+
+// excludedIf, as excluded_if=other value..., forbids the field when other
+// has one of the values
+func excludedIf(f Field) string {
+	other, values := splitCondition(f.Param)
+	if matches(f.Sibling(other), values) && f.Present() {
+		return fmt.Sprintf("%s must not be set when %s is %s", f.Label, other, strings.Join(values, " or "))
+	}
+	return ""
+}
+
+// splitCondition splits "other value..." into the field name and values
+func splitCondition(param string) (string, []string) {
+	words := strings.Fields(param)
+	if len(words) < 2 {
+		panic("validation: condition " + param + " needs a field and a value")
+	}
+	return words[0], words[1:]
+}
+
+// matches reports whether a field's value is one of values
+func matches(f Field, values []string) bool {
+	if !f.Value.IsValid() {
+		return false
+	}
+	text := fmt.Sprint(f.Value.Interface())
+	for _, value := range values {
+		if text == value {
+			return true
+		}
+	}
+	return false
+}
+
+// compare builds a rule comparing numbers by value, and strings and slices
+// by length, with the parameter. The phrases complete "<label> must be"
+// for each kind of value.
+func compare(ok func(value, param float64) bool, number, text, items string, schema func(Schema, string, reflect.Type)) Rule {
+	return Rule{
+		Check: func(f Field) string {
+			limit, err := strconv.ParseFloat(f.Param, 64)
+			if err != nil {
+				panic("validation: " + f.Param + " is not a number")
+			}
+			value, kind := measure(f.Value)
+			if ok(value, limit) {
+				return ""
+			}
+			switch kind {
+			case kindText:
+				return f.Label + " must be " + fmt.Sprintf(text, f.Param)
+			case kindList:
+				return f.Label + " must have " + fmt.Sprintf(items, f.Param)
+			default:
+				return f.Label + " must be " + fmt.Sprintf(number, f.Param)
+			}
+		},
+		Schema: schema,
+	}
+}
+
+// Kinds of values compared by size
+const (
+	kindNumber = iota
+	kindText
+	kindList
+)
+
+// measure returns the value of a number or the length of a string or slice
+func measure(value reflect.Value) (float64, int) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), kindText
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), kindList
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), kindNumber
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), kindNumber
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), kindNumber
+	}
+	panic("validation: cannot compare a " + value.Kind().String())
+}
+
+// oneOf, as oneof=a b c, requires the value to be one of the listed words
+func oneOf(f Field) string {
+	values := strings.Fields(f.Param)
+	if !matches(f, values) {
+		return fmt.Sprintf("%s must be one of %s", f.Label, strings.Join(values, ", "))
+	}
+	return ""
+}
+
+// patterns caches compiled pattern rules by expression
+var patterns sync.Map
+
+// pattern, as pattern=regexp, requires a string to match the expression
+func pattern(f Field) string {
+	var re *regexp.Regexp
+	if cached, ok := patterns.Load(f.Param); ok {
+		re = cached.(*regexp.Regexp)
+	} else {
+		re = regexp.MustCompile(f.Param)
+		patterns.Store(f.Param, re)
+	}
+	if !re.MatchString(f.Value.String()) {
+		return fmt.Sprintf("%s must match %s", f.Label, f.Param)
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema is a JSON Schema object as used in Swagger definitions
+type Schema map[string]interface{}
+
+// Description is the schema form of the validate tags of a struct
+type Description struct {
+	Properties map[string]Schema // Keywords of every validated field, by JSON name
+	Required   []string          // Fields with the required rule
+}
+
+// Describe returns the JSON Schema keywords equivalent to the validate tags
+// of a struct type. Every validated field gets an x-validate extension with
+// its tag, so rules without a schema equivalent, such as cross-field rules,
+// are documented as well.
+func Describe(v interface{}) Description {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	description := Description{Properties: map[string]Schema{}}
+	for _, field := range fieldsOf(t) {
+		if field.tag == "" {
+			continue
+		}
+		fieldType := t.Field(field.index).Type
+
+		schema := Schema{"x-validate": field.tag}
+		for _, r := range field.rules {
+			if r.name == "required" {
+				description.Required = append(description.Required, field.name)
+			}
+			if r.rule.Schema != nil {
+				r.rule.Schema(schema, r.param, fieldType)
+			}
+		}
+		if field.dive != nil {
+			items := Schema{}
+			for _, r := range field.dive {
+				if r.rule.Schema != nil {
+					r.rule.Schema(items, r.param, elemType(fieldType))
+				}
+			}
+			if len(items) > 0 {
+				schema["items"] = items
+			}
+		}
+		description.Properties[field.name] = schema
+	}
+	return description
+}
+
+// elemType returns the element type of a slice type, following pointers
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return t.Elem()
+	}
+	return t
+}
+
+// schemaKind classifies a type like measure classifies a value
+func schemaKind(t reflect.Type) int {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return kindText
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return kindList
+	}
+	return kindNumber
+}
+
+// minimumSchema describes a lower bound, excluding the limit itself if exclusive
+func minimumSchema(exclusive bool) func(Schema, string, reflect.Type) {
+	return func(schema Schema, param string, t reflect.Type) {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		switch schemaKind(t) {
+		case kindText:
+			schema["minLength"] = lengthLimit(limit, exclusive, 1)
+		case kindList:
+			schema["minItems"] = lengthLimit(limit, exclusive, 1)
+		default:
+			schema["minimum"] = limit
+			if exclusive {
+				schema["exclusiveMinimum"] = true
+			}
+		}
+	}
+}
+
+// maximumSchema describes an upper bound, excluding the limit itself if exclusive
+func maximumSchema(exclusive bool) func(Schema, string, reflect.Type) {
+	return func(schema Schema, param string, t reflect.Type) {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		switch schemaKind(t) {
+		case kindText:
+			schema["maxLength"] = lengthLimit(limit, exclusive, -1)
+		case kindList:
+			schema["maxItems"] = lengthLimit(limit, exclusive, -1)
+		default:
+			schema["maximum"] = limit
+			if exclusive {
+				schema["exclusiveMaximum"] = true
+			}
+		}
+	}
+}
+
+// lengthLimit turns a length bound into the inclusive integer JSON Schema
+// expects, moving an exclusive bound by step
+func lengthLimit(limit float64, exclusive bool, step int) int {
+	if exclusive {
+		return int(limit) + step
+	}
+	return int(limit)
+}
+
+// oneOfSchema lists the allowed values as an enum
+func oneOfSchema(schema Schema, param string, t reflect.Type) {
+	var values []interface{}
+	for _, word := range strings.Fields(param) {
+		if schemaKind(t) == kindNumber {
+			if number, err := strconv.ParseFloat(word, 64); err == nil {
+				values = append(values, number)
+				continue
+			}
+		}
+		values = append(values, word)
+	}
+	schema["enum"] = values
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// fieldRule is one rule of a validate tag with its parameter
+type fieldRule struct {
+	name  string
+	param string
+	rule  Rule
+}
+
+// structField is a struct field with its parsed validate tag
+type structField struct {
+	index int         // Index of the field in the struct
+	name  string      // JSON name
+	label string      // Name in messages
+	tag   string      // The validate tag as written
+	rules []fieldRule // Rules for the field itself
+	dive  []fieldRule // Rules for every element, nil without dive
+}
+
+// structFields caches the parsed fields of every struct type by reflect.Type
+var structFields sync.Map
+
+// fieldsOf returns the exported, JSON visible fields of a struct type
+func fieldsOf(t reflect.Type) []structField {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		label := sf.Tag.Get("label")
+		if label == "" {
+			label = labelOf(name)
+		}
+
+		field := structField{index: i, name: name, label: label, tag: sf.Tag.Get("validate")}
+		field.rules, field.dive = parseTag(field.tag)
+		fields = append(fields, field)
+	}
+
+	structFields.Store(t, fields)
+	return fields
+}
+
+// parseTag splits a validate tag into the rules for the field and, after
+// dive, the rules for its elements
+func parseTag(tag string) (rules, dive []fieldRule) {
+	target := &rules
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "pattern=") {
+			part, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		if name == "dive" {
+			dive = []fieldRule{}
+			target = &dive
+			continue
+		}
+		*target = append(*target, fieldRule{name: name, param: param, rule: lookup(name)})
+	}
+	return rules, dive
+}
+
+// labelOf turns a JSON name such as recency_half_life_days into
+// Recency half life days
+func labelOf(name string) string {
+	label := []rune(strings.ReplaceAll(name, "_", " "))
+	if len(label) > 0 {
+		label[0] = unicode.ToUpper(label[0])
+	}
+	return string(label)
+}
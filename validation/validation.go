@@ -0,0 +1,213 @@
+// Package validation checks structs against the rules declared in their
+// validate tags, e.g.
+//
+//	Price float64 `json:"price" validate:"gt=0"`
+//
+// Rules are separated by commas and take an optional parameter after =.
+// A pattern rule takes the rest of the tag as its parameter, so it must come
+// last. Rules after dive apply to every element of a slice. Nested structs are
+// validated as well, and their fields are reported with a dot, e.g.
+// product.price. Fields are named by their JSON names; a label tag overrides
+// the name used in messages.
+//
+// Apart from presence rules such as required, rules skip missing values,
+// i.e. nil pointers and empty strings.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FieldError describes why one field is invalid
+type FieldError struct {
+	Code    string `json:"code"`    // Name of the failed rule, e.g. required or gt
+	Message string `json:"message"` // Human readable explanation
+}
+
+// Errors maps the JSON path of every invalid field, e.g. price or
+// operations[2].product.sku, to the first rule it failed
+type Errors map[string]FieldError
+
+// Add records an error for a field unless the field already has one
+func (e Errors) Add(field, code, message string) {
+	if _, ok := e[field]; !ok {
+		e[field] = FieldError{Code: code, Message: message}
+	}
+}
+
+// Fields returns the invalid fields in sorted order
+func (e Errors) Fields() []string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Summary joins the messages of all errors into one line, in field order
+func (e Errors) Summary() string {
+	messages := make([]string, 0, len(e))
+	for _, field := range e.Fields() {
+		messages = append(messages, e[field].Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Field is a struct field being checked by a rule
+type Field struct {
+	Name   string        // JSON name of the field
+	Label  string        // Name of the field in messages, e.g. Recency half life days or Synonyms[2]
+	Value  reflect.Value // Value of the field with pointers dereferenced; invalid for a nil pointer
+	Param  string        // Parameter of the rule, e.g. 0 in gt=0
+	Parent reflect.Value // Struct holding the field, for cross-field rules
+	raw    reflect.Value // Value of the field as declared
+}
+
+// Present reports whether the field has a value: a non-nil pointer, a
+// non-blank string, a non-empty slice or map, or any other non-zero value.
+// A pointer to a zero number is present.
+func (f Field) Present() bool {
+	if !f.raw.IsValid() || f.raw.IsZero() || !f.Value.IsValid() {
+		return false
+	}
+	switch f.Value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(f.Value.String()) != ""
+	case reflect.Slice, reflect.Map:
+		return f.Value.Len() > 0
+	}
+	return true
+}
+
+// Sibling returns the field of the parent struct with the given JSON name.
+// It panics if there is none, as that is a mistake in a validate tag.
+func (f Field) Sibling(name string) Field {
+	for _, field := range fieldsOf(f.Parent.Type()) {
+		if field.name == name {
+			raw := f.Parent.Field(field.index)
+			return Field{Name: field.name, Label: field.label, Value: deref(raw), Parent: f.Parent, raw: raw}
+		}
+	}
+	panic(fmt.Sprintf("validation: %s has no field %q", f.Parent.Type(), name))
+}
+
+// Rule is a named check that can be used in validate tags
+type Rule struct {
+	// Check returns a message explaining why the field is invalid, or an
+	// empty string if it is valid
+	Check func(f Field) string
+
+	// Always makes the rule run for missing values, which other rules skip.
+	// It is set for rules about presence, such as required.
+	Always bool
+
+	// Schema adds the JSON Schema keywords equivalent to the rule, if any,
+	// for a field of type t
+	Schema func(schema Schema, param string, t reflect.Type)
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{}
+)
+
+// Register makes a rule available under a name. It panics if the name is
+// already taken. Custom rules are registered from init functions so that
+// they exist before the first validation.
+func Register(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if _, ok := rules[name]; ok {
+		panic("validation: rule " + name + " registered twice")
+	}
+	rules[name] = rule
+}
+
+// lookup returns a registered rule, panicking for unknown names as they are
+// a mistake in a validate tag
+func lookup(name string) Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	rule, ok := rules[name]
+	if !ok {
+		panic("validation: unknown rule " + name)
+	}
+	return rule
+}
+
+// Struct validates a struct, or a pointer to one, and returns the errors of
+// all invalid fields, or nil if it is valid
+func Struct(v interface{}) Errors {
+	errs := Errors{}
+	validateStruct(deref(reflect.ValueOf(v)), "", errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateStruct checks every field of a struct value
+func validateStruct(value reflect.Value, prefix string, errs Errors) {
+	if !value.IsValid() || value.Kind() != reflect.Struct {
+		return
+	}
+	for _, field := range fieldsOf(value.Type()) {
+		raw := value.Field(field.index)
+		f := Field{Name: field.name, Label: field.label, Value: deref(raw), Parent: value, raw: raw}
+		path := prefix + field.name
+		if !checkRules(f, field.rules, path, errs) {
+			continue
+		}
+
+		if field.dive != nil {
+			if f.Value.IsValid() && f.Value.Kind() == reflect.Slice {
+				for i := 0; i < f.Value.Len(); i++ {
+					element := f.Value.Index(i)
+					label := fmt.Sprintf("%s[%d]", field.label, i)
+					e := Field{Name: field.name, Label: label, Value: deref(element), Parent: value, raw: element}
+					elementPath := fmt.Sprintf("%s[%d]", path, i)
+					if checkRules(e, field.dive, elementPath, errs) {
+						validateStruct(e.Value, elementPath+".", errs)
+					}
+				}
+			}
+			continue
+		}
+		validateStruct(f.Value, path+".", errs)
+	}
+}
+
+// checkRules runs rules in order until one fails, recording its error. It
+// reports whether all rules passed.
+func checkRules(f Field, fieldRules []fieldRule, path string, errs Errors) bool {
+	missing := !f.Value.IsValid() || (f.Value.Kind() == reflect.String && f.Value.Len() == 0)
+	for _, r := range fieldRules {
+		if missing && !r.rule.Always {
+			continue
+		}
+		f.Param = r.param
+		if msg := r.rule.Check(f); msg != "" {
+			errs.Add(path, r.name, msg)
+			return false
+		}
+	}
+	return true
+}
+
+// deref follows pointers, returning an invalid value for a nil pointer
+func deref(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
@@ -0,0 +1,256 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ruleTarget has one field per rule under test
+type ruleTarget struct {
+	Name     string   `json:"name" validate:"required,max=5"`
+	Price    *float64 `json:"price" validate:"gt=0,lt=100"`
+	Quantity int      `json:"quantity" validate:"gte=0,lte=10"`
+	Code     string   `json:"code" validate:"min=2,pattern=^[A-Z]+(-[0-9]+)?$"`
+	Kind     string   `json:"kind" validate:"oneof=book lamp"`
+	Author   string   `json:"author" validate:"required_if=kind book,excluded_if=kind lamp"`
+	Percent  *float64 `json:"percent" validate:"required_without=amount,excluded_with=amount"`
+	Amount   *float64 `json:"amount"`
+	Currency string   `json:"currency" validate:"required_with=amount"`
+	Tags     []string `json:"tags" validate:"max=2,dive,min=3"`
+}
+
+func float(v float64) *float64 { return &v }
+
+// validTarget returns a ruleTarget that passes every rule
+func validTarget() ruleTarget {
+	return ruleTarget{Name: "Lamba", Price: float(10), Code: "AB-1", Kind: "lamp", Percent: float(5), Tags: []string{"ışık"}}
+}
+
+func TestStructRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*ruleTarget)
+		field   string
+		code    string
+		message string
+	}{
+		{"required", func(v *ruleTarget) { v.Name = "" }, "name", "required", "Name is required"},
+		{"required with blank string", func(v *ruleTarget) { v.Name = "  " }, "name", "required", "Name is required"},
+		{"max on text", func(v *ruleTarget) { v.Name = "Abajurlar" }, "name", "max", "Name must be at most 5 characters long"},
+		{"max counts characters", func(v *ruleTarget) { v.Name = "Işıklı" }, "name", "max", "Name must be at most 5 characters long"},
+		{"gt", func(v *ruleTarget) { v.Price = float(0) }, "price", "gt", "Price must be greater than 0"},
+		{"lt", func(v *ruleTarget) { v.Price = float(100) }, "price", "lt", "Price must be less than 100"},
+		{"gte", func(v *ruleTarget) { v.Quantity = -1 }, "quantity", "gte", "Quantity must be at least 0"},
+		{"lte", func(v *ruleTarget) { v.Quantity = 11 }, "quantity", "lte", "Quantity must be at most 10"},
+		{"min on text", func(v *ruleTarget) { v.Code = "A" }, "code", "min", "Code must be at least 2 characters long"},
+		{"pattern", func(v *ruleTarget) { v.Code = "ab-1" }, "code", "pattern", "Code must match ^[A-Z]+(-[0-9]+)?$"},
+		{"oneof", func(v *ruleTarget) { v.Kind = "desk" }, "kind", "oneof", "Kind must be one of book, lamp"},
+		{"required_if", func(v *ruleTarget) { v.Kind = "book" }, "author", "required_if", "Author is required when kind is book"},
+		{"excluded_if", func(v *ruleTarget) { v.Author = "Ada" }, "author", "excluded_if", "Author must not be set when kind is lamp"},
+		{"required_without", func(v *ruleTarget) { v.Percent = nil }, "percent", "required_without", "Percent is required when amount is not set"},
+		{"excluded_with", func(v *ruleTarget) { v.Amount, v.Currency = float(1), "TRY" }, "percent", "excluded_with", "Percent must not be set when amount is set"},
+		{"required_with", func(v *ruleTarget) { v.Percent, v.Amount = nil, float(1) }, "currency", "required_with", "Currency is required when amount is set"},
+		{"max on list", func(v *ruleTarget) { v.Tags = []string{"bir", "iki", "üçüncü"} }, "tags", "max", "Tags must have at most 2 items"},
+		{"dive", func(v *ruleTarget) { v.Tags = []string{"ışık", "ev"} }, "tags[1]", "min", "Tags[1] must be at least 3 characters long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validTarget()
+			tt.change(&v)
+			want := Errors{tt.field: {Code: tt.code, Message: tt.message}}
+			if got := Struct(&v); !reflect.DeepEqual(got, want) {
+				t.Errorf("Struct = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStructValid(t *testing.T) {
+	v := validTarget()
+	if errs := Struct(v); errs != nil {
+		t.Errorf("Struct of a valid value = %v", errs)
+	}
+
+	// Rules other than presence rules skip missing values
+	v.Price, v.Code, v.Kind, v.Tags = nil, "", "", nil
+	if errs := Struct(&v); errs != nil {
+		t.Errorf("Struct with missing optional fields = %v", errs)
+	}
+
+	// A pointer to zero is present
+	v = validTarget()
+	v.Percent = float(0)
+	if errs := Struct(&v); errs != nil {
+		t.Errorf("Struct with percent 0 = %v", errs)
+	}
+}
+
+// nestedTarget checks the paths and labels of nested fields
+type nestedTarget struct {
+	Operations []nestedOperation `json:"operations" validate:"required,dive"`
+	Product    *nestedProduct    `json:"product"`
+}
+
+type nestedOperation struct {
+	Op      string         `json:"op" validate:"oneof=create delete"`
+	Product *nestedProduct `json:"product"`
+}
+
+type nestedProduct struct {
+	SKU   string  `json:"sku" label:"SKU" validate:"required"`
+	Price float64 `json:"price" validate:"gt=0"`
+}
+
+func TestStructFieldPaths(t *testing.T) {
+	v := nestedTarget{
+		Operations: []nestedOperation{
+			{Op: "create", Product: &nestedProduct{SKU: "A", Price: 1}},
+			{Op: "update"},
+			{Op: "create", Product: &nestedProduct{Price: -1}},
+		},
+		Product: &nestedProduct{SKU: "B"},
+	}
+	want := Errors{
+		"operations[1].op":            {Code: "oneof", Message: "Op must be one of create, delete"},
+		"operations[2].product.sku":   {Code: "required", Message: "SKU is required"},
+		"operations[2].product.price": {Code: "gt", Message: "Price must be greater than 0"},
+		"product.price":               {Code: "gt", Message: "Price must be greater than 0"},
+	}
+	got := Struct(v)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct = %v, want %v", got, want)
+	}
+
+	fields := []string{"operations[1].op", "operations[2].product.price", "operations[2].product.sku", "product.price"}
+	if !reflect.DeepEqual(got.Fields(), fields) {
+		t.Errorf("Fields = %v, want %v", got.Fields(), fields)
+	}
+	summary := "Op must be one of create, delete; Price must be greater than 0; SKU is required; Price must be greater than 0"
+	if got.Summary() != summary {
+		t.Errorf("Summary = %q, want %q", got.Summary(), summary)
+	}
+
+	// Errors are reported as a JSON object keyed by path
+	data, _ := json.Marshal(Errors{"product.price": {Code: "gt", Message: "Price must be greater than 0"}})
+	if string(data) != `{"product.price":{"code":"gt","message":"Price must be greater than 0"}}` {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestErrorsAddKeepsFirst(t *testing.T) {
+	errs := Errors{}
+	errs.Add("price", "gt", "first")
+	errs.Add("price", "lt", "second")
+	if errs["price"].Code != "gt" {
+		t.Errorf("Add replaced the first error: %v", errs)
+	}
+}
+
+// expectPanic runs f and returns the message it panicked with
+func expectPanic(t *testing.T, f func()) string {
+	t.Helper()
+	var message string
+	func() {
+		defer func() { message, _ = recover().(string) }()
+		f()
+	}()
+	if message == "" {
+		t.Fatal("no panic")
+	}
+	return message
+}
+
+func TestUnknownTags(t *testing.T) {
+	type unknownRule struct {
+		Name string `json:"name" validate:"required,shiny"`
+	}
+	if msg := expectPanic(t, func() { Struct(unknownRule{}) }); msg != "validation: unknown rule shiny" {
+		t.Errorf("panic = %q", msg)
+	}
+
+	type unknownSibling struct {
+		Name string `json:"name" validate:"required_with=nickname"`
+	}
+	if msg := expectPanic(t, func() { Struct(unknownSibling{}) }); !strings.Contains(msg, `has no field "nickname"`) {
+		t.Errorf("panic = %q", msg)
+	}
+
+	type badLimit struct {
+		Price float64 `json:"price" validate:"gt=zero"`
+	}
+	if msg := expectPanic(t, func() { Struct(badLimit{Price: 1}) }); msg != "validation: zero is not a number" {
+		t.Errorf("panic = %q", msg)
+	}
+
+	if msg := expectPanic(t, func() { Register("required", Rule{}) }); msg != "validation: rule required registered twice" {
+		t.Errorf("panic = %q", msg)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("even", Rule{Check: func(f Field) string {
+		if f.Value.Int()%2 != 0 {
+			return f.Label + " must be even"
+		}
+		return ""
+	}})
+	type target struct {
+		Pairs int `json:"pairs_count" validate:"even"`
+	}
+	want := Errors{"pairs_count": {Code: "even", Message: "Pairs count must be even"}}
+	if got := Struct(target{Pairs: 3}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Errors
+	}{
+		{"number as string", `{"price": "ten"}`, Errors{"price": {Code: "type", Message: "Price must be a number"}}},
+		{"fraction as whole number", `{"quantity": 1.5}`, Errors{"quantity": {Code: "type", Message: "Quantity must be a whole number"}}},
+		{"nested field", `{"operations": [{}, {"product": {"sku": 5}}]}`, Errors{"operations[1].product.sku": {Code: "type", Message: "SKU must be a string"}}},
+		{"unknown field", `{"colour": "red"}`, Errors{"colour": {Code: "unknown", Message: "colour is not a known field"}}},
+		{"syntax error", `{"price": `, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				Price      *float64          `json:"price"`
+				Quantity   int               `json:"quantity"`
+				Operations []nestedOperation `json:"operations"`
+			}
+			decoder := json.NewDecoder(bytes.NewReader([]byte(tt.body)))
+			decoder.DisallowUnknownFields()
+			err := decoder.Decode(&v)
+			if err == nil {
+				t.Fatal("Decode succeeded")
+			}
+			if got := DecodeErrors(err, &v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeErrors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	description := Describe(&ruleTarget{})
+	if !reflect.DeepEqual(description.Required, []string{"name"}) {
+		t.Errorf("Required = %v", description.Required)
+	}
+	name := description.Properties["name"]
+	if name["maxLength"] != 5 || name["x-validate"] != "required,max=5" {
+		t.Errorf("name schema = %v", name)
+	}
+	if _, ok := description.Properties["amount"]; ok {
+		t.Error("a field without rules has a schema")
+	}
+	if items, _ := description.Properties["tags"]["items"].(Schema); items["minLength"] != 3 {
+		t.Errorf("tags schema = %v", description.Properties["tags"])
+	}
+}